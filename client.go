@@ -0,0 +1,104 @@
+package urlfmt
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// clientConfig is the configuration for a http.Client made by NewClient, which is built up by applying each
+// ClientOption in turn.
+type clientConfig struct {
+	client    *http.Client
+	transport *http.Transport
+}
+
+// ClientOption customises a http.Client made by NewClient. Options are applied in the order that they are given, so
+// later options override earlier ones.
+type ClientOption func(config *clientConfig)
+
+// WithClientTimeout sets the http.Client.Timeout of the client, which covers the entire request, including reading the
+// response body. Note that the requests made by Soup, JSON, and the like are also subject to DefaultTimeout, unless
+// their context already has a deadline.
+func WithClientTimeout(d time.Duration) ClientOption {
+	return func(config *clientConfig) { config.client.Timeout = d }
+}
+
+// WithProxy makes the client send every request through the proxy at the given URL. By default, the client uses the
+// proxy given by the environment (see http.ProxyFromEnvironment). A nil URL disables proxying.
+func WithProxy(proxy *url.URL) ClientOption {
+	return func(config *clientConfig) {
+		config.transport.Proxy = nil
+		if proxy != nil {
+			config.transport.Proxy = http.ProxyURL(proxy)
+		}
+	}
+}
+
+// WithInsecureSkipVerify makes the client accept any TLS certificate presented by the server, and any host name in
+// that certificate. This should only be used for testing against internal servers that use self-signed certificates.
+func WithInsecureSkipVerify() ClientOption {
+	return func(config *clientConfig) {
+		if config.transport.TLSClientConfig == nil {
+			config.transport.TLSClientConfig = &tls.Config{}
+		}
+		config.transport.TLSClientConfig.InsecureSkipVerify = true
+	}
+}
+
+// WithMaxIdleConns sets the maximum number of idle (keep-alive) connections that the client keeps open, both in total
+// and per host. Zero means no limit in total, and http.DefaultMaxIdleConnsPerHost per host.
+func WithMaxIdleConns(n int) ClientOption {
+	return func(config *clientConfig) {
+		config.transport.MaxIdleConns = n
+		config.transport.MaxIdleConnsPerHost = n
+	}
+}
+
+// WithHTTP2 sets whether the client attempts to use HTTP/2 when connecting to servers over TLS. By default, the client
+// behaves the same as http.DefaultClient, which attempts HTTP/2 and falls back to HTTP/1.1 if the server does not
+// support it. When disabled, the client is pinned to HTTP/1.1 by clearing the transport's TLSNextProto, and by no
+// longer offering "h2" during the TLS handshake, which is useful for servers that misbehave under HTTP/2.
+func WithHTTP2(enabled bool) ClientOption {
+	return func(config *clientConfig) {
+		config.transport.ForceAttemptHTTP2 = enabled
+		config.transport.TLSNextProto = nil
+		if enabled {
+			return
+		}
+
+		config.transport.TLSNextProto = make(map[string]func(authority string, c *tls.Conn) http.RoundTripper)
+		if tlsConfig := config.transport.TLSClientConfig; tlsConfig != nil {
+			protos := make([]string, 0, len(tlsConfig.NextProtos))
+			for _, proto := range tlsConfig.NextProtos {
+				if proto != "h2" {
+					protos = append(protos, proto)
+				}
+			}
+			tlsConfig.NextProtos = protos
+		}
+	}
+}
+
+// NewClient creates a new http.Client that has its own http.Transport, which is configured by applying each of the
+// given ClientOption(s). The transport starts out as a clone of http.DefaultTransport, so a client made without any
+// options behaves the same as http.DefaultClient. As the transport pools connections, the client should be created
+// once and reused across requests, by passing it to SoupWithClient, JSONWithClient, or WithClient, e.g.
+//
+//	client := NewClient(WithClientTimeout(time.Second*30), WithMaxIdleConns(10))
+//	doc, resp, err := SteamAppPage.SoupOpts([]any{477160}, WithClient(client))
+func NewClient(opts ...ClientOption) *http.Client {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if ok {
+		transport = transport.Clone()
+	} else {
+		transport = &http.Transport{Proxy: http.ProxyFromEnvironment}
+	}
+
+	config := &clientConfig{client: &http.Client{Transport: transport}, transport: transport}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config.client
+}
@@ -0,0 +1,100 @@
+package urlfmt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestNewClient(t *testing.T) {
+	proxy, _ := url.Parse("http://proxy.internal:3128")
+	client := NewClient(
+		WithClientTimeout(time.Second*30),
+		WithProxy(proxy),
+		WithInsecureSkipVerify(),
+		WithMaxIdleConns(7),
+	)
+	if client.Timeout != time.Second*30 {
+		t.Errorf("expected a timeout of 30s, got %v", client.Timeout)
+	}
+
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected a *http.Transport, got %T", client.Transport)
+	}
+	if transport == http.DefaultTransport {
+		t.Errorf("expected the transport to be separate from http.DefaultTransport")
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://store.steampowered.com/app/477160", nil)
+	if proxyURL, err := transport.Proxy(req); err != nil || proxyURL.String() != proxy.String() {
+		t.Errorf("expected requests to be proxied through %s, got %v (%v)", proxy, proxyURL, err)
+	}
+	if transport.TLSClientConfig == nil || !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Errorf("expected InsecureSkipVerify to be set")
+	}
+	if transport.MaxIdleConns != 7 || transport.MaxIdleConnsPerHost != 7 {
+		t.Errorf("expected 7 max idle conns, got %d (%d per host)", transport.MaxIdleConns, transport.MaxIdleConnsPerHost)
+	}
+
+	if transport, _ = NewClient(WithProxy(nil)).Transport.(*http.Transport); transport.Proxy != nil {
+		t.Errorf("expected proxying to be disabled")
+	}
+	if defaultTransport := http.DefaultTransport.(*http.Transport); defaultTransport.TLSClientConfig != nil && defaultTransport.TLSClientConfig.InsecureSkipVerify {
+		t.Errorf("expected http.DefaultTransport to be left unmodified")
+	}
+}
+
+func TestNewClient_fetch(t *testing.T) {
+	// newTLSServer is not used, as it swaps http.DefaultTransport for one that trusts the server's certificate
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+	// The server's certificate is self-signed, so it is only trusted when verification is skipped
+	host := server.Listener.Addr().String()
+	const Endpoint URL = "%s://%s/ok"
+	if _, _, err := Endpoint.JSONWithClient(NewClient(), nil, host); err == nil {
+		t.Errorf("expected an error for a self-signed certificate")
+	}
+	jsonBody, _, err := Endpoint.JSONWithClient(NewClient(WithInsecureSkipVerify()), nil, host)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if jsonBody["ok"] != true {
+		t.Errorf("unexpected JSON %v", jsonBody)
+	}
+}
+
+func TestWithHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	const Endpoint URL = "%s://%s/proto"
+	for _, test := range []struct {
+		enabled  bool
+		expected string
+	}{
+		{true, "HTTP/2.0"},
+		{false, "HTTP/1.1"},
+	} {
+		client := NewClient(WithInsecureSkipVerify(), WithHTTP2(test.enabled))
+		transport := client.Transport.(*http.Transport)
+		if transport.ForceAttemptHTTP2 != test.enabled {
+			t.Errorf("expected ForceAttemptHTTP2 to be %t, got %t", test.enabled, transport.ForceAttemptHTTP2)
+		}
+
+		_, resp, err := Endpoint.SoupWithClient(client, nil, server.Listener.Addr().String())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Proto != test.expected {
+			t.Errorf("expected the request to be made using %s, got %s", test.expected, resp.Proto)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package urlfmt
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"regexp"
+	"strings"
+)
+
+// Compiled is a URL format that has been prepared for repeated use by URL.Compile. The regex, the verbs, and the
+// parser for each group within the regex are computed once, so that Match, ExtractArgs, and Fill do not need to
+// re-scan the URL format on each call. A Compiled is immutable, and so is safe for concurrent use. As the parsers are
+// looked up when the URL format is compiled, verbs registered afterwards (see RegisterVerb) are not picked up.
+type Compiled struct {
+	url           URL
+	pattern       *regexp.Regexp
+	verbs         []VerbInfo
+	groupPatterns []string
+	parsers       []regexParserFunc
+	// format is the format that can be passed straight to fmt.Sprintf by Fill. It is empty if the URL format contains
+	// optional sections or repeated query parameter markers, as these depend upon the args given to Fill.
+	format string
+}
+
+// Compile prepares the URL format for repeated use. An error is returned if the URL format produces an invalid regex.
+func (u URL) Compile() (*Compiled, error) {
+	pattern, err := u.RegexErr()
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot compile %s", u.String())
+	}
+
+	c := &Compiled{
+		url:           u,
+		pattern:       pattern,
+		verbs:         u.Verbs(),
+		groupPatterns: captureGroups(pattern.String()),
+	}
+	c.parsers = make([]regexParserFunc, len(c.groupPatterns))
+	for i, groupPattern := range c.groupPatterns {
+		c.parsers[i], _ = parserFor(groupPattern)
+	}
+	if format := u.String(); !strings.Contains(format, optionalOpen) && !repeatedPattern.MatchString(format) {
+		c.format = fmtFormat(format)
+	}
+	return c, nil
+}
+
+// MustCompile is the same as URL.Compile, except that it panics if the URL format produces an invalid regex.
+func (u URL) MustCompile() *Compiled {
+	c, err := u.Compile()
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// URL returns the URL format that was compiled.
+func (c *Compiled) URL() URL { return c.url }
+
+// Regex returns the compiled regex for the URL format (see URL.Regex).
+func (c *Compiled) Regex() *regexp.Regexp { return c.pattern }
+
+// Verbs returns information on each of the string interpolation verbs within the URL format (see URL.Verbs).
+func (c *Compiled) Verbs() []VerbInfo {
+	return append([]VerbInfo(nil), c.verbs...)
+}
+
+// Match is the same as URL.Match.
+func (c *Compiled) Match(url string) bool {
+	return c.pattern.MatchString(c.url.candidate(url))
+}
+
+// ExtractArgs is the same as URL.ExtractArgsErr.
+func (c *Compiled) ExtractArgs(url string) (args []any, err error) {
+	candidate := c.url.candidate(url)
+	loc := c.pattern.FindStringSubmatchIndex(candidate)
+	if loc == nil {
+		return nil, errors.Wrapf(ErrNoMatch, "%q does not match %s", url, c.pattern.String())
+	}
+
+	args = make([]any, len(c.groupPatterns))
+	for i, parseFunc := range c.parsers {
+		start, end := loc[2*i+2], loc[2*i+3]
+		if start < 0 {
+			continue
+		}
+		group := candidate[start:end]
+		if parseFunc == nil {
+			args[i] = group
+			continue
+		}
+		if args[i], err = parseFunc(group); err != nil {
+			return nil, &ParseError{Value: group, Pattern: c.groupPatterns[i], Err: err}
+		}
+	}
+	return args, nil
+}
+
+// Fill is the same as URL.Fill.
+func (c *Compiled) Fill(args ...any) string {
+	if c.format == "" {
+		return c.url.Fill(args...)
+	}
+	if c.url.hasProtocolVerb() {
+		args = append([]any{"https"}, args...)
+	}
+	return fmt.Sprintf(c.format, args...)
+}
@@ -0,0 +1,72 @@
+package urlfmt
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestURL_Compile(t *testing.T) {
+	for _, test := range []struct {
+		u    URL
+		args []any
+		url  string
+	}{
+		{"%s://store.steampowered.com/app/%d", []any{int64(477160)}, "https://store.steampowered.com/app/477160"},
+		{"%s://%s.itch.io/%s", []any{"hempuli", "baba-files-taxes"}, "https://hempuli.itch.io/baba-files-taxes"},
+		{"/app/%d/reviews?filter=%s", []any{int64(477160), "recent"}, "/app/477160/reviews?filter=recent"},
+		{"%s://localhost%{:%d%}/app/%d", []any{nil, int64(5)}, "https://localhost/app/5"},
+		{"%s://example.com/search?{tags...}&page=%d", []any{[]string{"rpg", "co op"}, int64(2)}, "https://example.com/search?tags=rpg&tags=co+op&page=2"},
+	} {
+		t.Run(string(test.u), func(t *testing.T) {
+			c, err := test.u.Compile()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual := c.Fill(test.args...); actual != test.url || actual != test.u.Fill(test.args...) {
+				t.Errorf("expected %q, got %q", test.url, actual)
+			}
+			if !c.Match(test.url) {
+				t.Errorf("expected %q to match", test.url)
+			}
+			if c.Regex().String() != test.u.Regex().String() {
+				t.Errorf("expected regex %s, got %s", test.u.Regex(), c.Regex())
+			}
+			if !reflect.DeepEqual(c.Verbs(), test.u.Verbs()) {
+				t.Errorf("expected verbs %+v, got %+v", test.u.Verbs(), c.Verbs())
+			}
+
+			var wg sync.WaitGroup
+			for i := 0; i < 8; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					args, err := c.ExtractArgs(test.url)
+					if err != nil {
+						t.Errorf("unexpected error: %v", err)
+					} else if !reflect.DeepEqual(args, test.args) {
+						t.Errorf("expected %#v, got %#v", test.args, args)
+					}
+				}()
+			}
+			wg.Wait()
+		})
+	}
+}
+
+func BenchmarkCompiled_Match(b *testing.B) {
+	const SteamAppReviews URL = "%s://store.steampowered.com/appreviews/%d?json=1&cursor=%s&language=%s"
+	const input = "https://store.steampowered.com/appreviews/477160?json=1&cursor=AoJ4&language=all"
+	c := SteamAppReviews.MustCompile()
+
+	b.Run("URL", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			SteamAppReviews.Match(input)
+		}
+	})
+	b.Run("Compiled", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			c.Match(input)
+		}
+	})
+}
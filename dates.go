@@ -0,0 +1,59 @@
+package urlfmt
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// DateLayout is the layout of the ISO 8601 dates matched by DatePattern, e.g. "2023-11-05".
+const DateLayout = "2006-01-02"
+
+// DatePattern is a regex pattern that matches an ISO 8601 date, e.g. "2023-11-05". It can be registered as a custom
+// verb, along with ParseDate, to extract the dates within URLs as time.Time values:
+//
+//	err := RegisterVerb('D', DatePattern, ParseDate)
+//	const ArchivePage URL = "%s://example.com/archive/%D/"
+//	args, err := ArchivePage.ExtractArgsErr("https://example.com/archive/2023-11-05/")
+//
+// As custom verbs are filled using "%v", the date should be formatted using DateLayout when filling.
+const DatePattern = `(\d{4}-\d{2}-\d{2})`
+
+// ParseDate parses a string matched by DatePattern into a time.Time in UTC. It is intended to be used as the parser for
+// a custom verb registered with DatePattern (see RegisterVerb).
+func ParseDate(s string) (any, error) {
+	return time.Parse(DateLayout, s)
+}
+
+// AsTime converts an arg extracted by URL.ExtractArgs to a time.Time. Integer args are treated as Unix timestamps in
+// seconds, such as the "start_date" and "end_date" query parameters of Steam's app reviews, and are converted to a
+// time.Time in UTC. String args are parsed using either time.RFC3339 or DateLayout, and time.Time args are returned as
+// is. An error is returned for any other type of arg.
+//
+//	args := SteamAppReviews.ExtractArgs(url)
+//	startDate, err := AsTime(args[6])
+func AsTime(arg any) (time.Time, error) {
+	switch arg := arg.(type) {
+	case time.Time:
+		return arg, nil
+	case string:
+		if t, err := time.Parse(time.RFC3339, arg); err == nil {
+			return t, nil
+		}
+		t, err := time.Parse(DateLayout, arg)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 timestamp nor an ISO 8601 date", arg)
+		}
+		return t, nil
+	}
+
+	val := reflect.ValueOf(arg)
+	switch {
+	case val.CanInt():
+		return time.Unix(val.Int(), 0).UTC(), nil
+	case val.CanUint():
+		return time.Unix(int64(val.Uint()), 0).UTC(), nil
+	default:
+		return time.Time{}, fmt.Errorf("cannot convert %v of type %T to a time.Time", arg, arg)
+	}
+}
@@ -0,0 +1,56 @@
+package urlfmt
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func ExampleRegisterVerb_date() {
+	if err := RegisterVerb('D', DatePattern, ParseDate); err != nil {
+		panic(err)
+	}
+
+	const ArchivePage URL = "%s://example.com/archive/%D/posts/%d"
+	url := ArchivePage.Fill(time.Date(2023, time.November, 5, 0, 0, 0, 0, time.UTC).Format(DateLayout), 3)
+	fmt.Println(url)
+	args, err := ArchivePage.ExtractArgsErr(url)
+	fmt.Println(args[0].(time.Time).Weekday(), args[1], err)
+	// Output:
+	// https://example.com/archive/2023-11-05/posts/3
+	// Sunday 3 <nil>
+}
+
+func TestAsTime(t *testing.T) {
+	const SteamAppReviews URL = "%s://store.steampowered.com/appreviews/%d?json=1&start_date=%d&end_date=%d"
+	args, err := SteamAppReviews.ExtractArgsErr(SteamAppReviews.Fill(477160, 1699142400, 1699228800))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, test := range []struct {
+		arg      any
+		expected time.Time
+		err      bool
+	}{
+		{args[1], time.Date(2023, time.November, 5, 0, 0, 0, 0, time.UTC), false},
+		{args[2], time.Date(2023, time.November, 6, 0, 0, 0, 0, time.UTC), false},
+		{uint32(0), time.Unix(0, 0).UTC(), false},
+		{"2023-11-05", time.Date(2023, time.November, 5, 0, 0, 0, 0, time.UTC), false},
+		{"2023-11-05T12:30:00Z", time.Date(2023, time.November, 5, 12, 30, 0, 0, time.UTC), false},
+		{time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), false},
+		{"yesterday", time.Time{}, true},
+		{1.5, time.Time{}, true},
+		{nil, time.Time{}, true},
+	} {
+		actual, err := AsTime(test.arg)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("expected an error converting %v, got %v", test.arg, actual)
+		case !test.err && err != nil:
+			t.Errorf("unexpected error converting %v: %v", test.arg, err)
+		case !actual.Equal(test.expected):
+			t.Errorf("expected %v to be converted to %v, got %v", test.arg, test.expected, actual)
+		}
+	}
+}
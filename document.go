@@ -0,0 +1,57 @@
+//go:build goquery
+
+package urlfmt
+
+import (
+	"bytes"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+	"net/http"
+	"time"
+)
+
+// Document fetches the URL using the default HTTP client, then parses the returned HTML page into a goquery.Document.
+// This is an alternative to Soup for those who would rather use goquery's selectors. It also returns the http.Response
+// object returned by the request. A http.Request can be provided, but if nil is provided then a default
+// http.MethodGet http.Request will be constructed instead. Document is only available when building with the "goquery"
+// build tag, so that the goquery dependency is not forced on those that don't need it.
+func (u URL) Document(req *http.Request, args ...any) (doc *goquery.Document, resp *http.Response, err error) {
+	var body []byte
+	if body, resp, err = u.fetch(pageFetchMessages, req, args...); err != nil {
+		return
+	}
+
+	if doc, err = goquery.NewDocumentFromReader(bytes.NewReader(body)); err != nil {
+		err = &ParseError{
+			Value: string(body),
+			Err:   errors.Wrapf(err, "HTML could not be parsed from response from %s", resp.Request.URL.String()),
+		}
+	}
+	return
+}
+
+// RetryDocument will run Document with the given args and try the given function. If the function returns an error
+// then the function will be retried up to a total of the given number of maxTries. If minDelay is given, and is not 0,
+// then before the function is retried it will sleep for (maxTries + 1 - currentTries) * minDelay. If a non-nil
+// http.Request is provided then it will be used to fetch the page for the goquery.Document, otherwise a default
+// http.MethodGet http.Request will be constructed instead. Use RetryDocumentWith to configure the backoff strategy.
+func (u URL) RetryDocument(req *http.Request, maxTries int, minDelay time.Duration, try func(doc *goquery.Document, resp *http.Response) error, args ...any) error {
+	return u.RetryDocumentWith(req, RetryConfig{MaxTries: maxTries, MinDelay: minDelay, Backoff: LinearBackoff}, try, args...)
+}
+
+// RetryDocumentWith will run Document with the given args and try the given function. If the function returns an
+// error then the function will be retried according to the given RetryConfig. If a non-nil http.Request is provided
+// then it will be used to fetch the page for the goquery.Document, otherwise a default http.MethodGet http.Request will
+// be constructed instead.
+func (u URL) RetryDocumentWith(req *http.Request, config RetryConfig, try func(doc *goquery.Document, resp *http.Response) error, args ...any) error {
+	return config.retry(func(currentTry int) (resp *http.Response, err error) {
+		var doc *goquery.Document
+		if doc, resp, err = u.Document(req, args...); err != nil {
+			return resp, errors.Wrapf(err, "ran out of tries (%d total) whilst requesting Document for %s", config.MaxTries, u.String())
+		}
+		if err = try(doc, resp); err != nil {
+			return resp, errors.Wrapf(err, "ran out of tries (%d total) whilst calling try function for %s", config.MaxTries, u.String())
+		}
+		return resp, nil
+	})
+}
@@ -0,0 +1,53 @@
+//go:build goquery
+
+package urlfmt
+
+import (
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ExampleURL_Document() {
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	fmt.Printf("Getting name of app 477160 from %s:\n", SteamAppPage.Fill(477160))
+	if doc, _, err := SteamAppPage.Document(nil, 477160); err != nil {
+		fmt.Printf("Could not get document for %s, because %s", SteamAppPage.Fill(477160), err.Error())
+	} else {
+		fmt.Println(strings.TrimSpace(doc.Find("#appHubAppName").Text()))
+	}
+}
+
+func TestURL_RetryDocument(t *testing.T) {
+	requests := 0
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `<html><body><div id="appHubAppName">Human: Fall Flat</div><p>%s</p></body></html>`, r.URL.Path)
+	}))
+
+	const SteamAppPage URL = "%s://%s/app/%d"
+	if err := SteamAppPage.RetryDocument(nil, 3, time.Millisecond, func(doc *goquery.Document, resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("status %s", resp.Status)
+		}
+		if name := doc.Find("#appHubAppName").Text(); name != "Human: Fall Flat" {
+			t.Errorf("expected app name %q, got %q", "Human: Fall Flat", name)
+		}
+		if path := doc.Find("p").Text(); path != "/app/477160" {
+			t.Errorf("expected path %q, got %q", "/app/477160", path)
+		}
+		return nil
+	}, host, 477160); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+}
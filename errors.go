@@ -0,0 +1,65 @@
+package urlfmt
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNoMatch is returned when a URL does not match a URL format.
+	ErrNoMatch = errors.New("URL does not match the format")
+	// ErrGroupCountMismatch is returned when the number of groups matched by the regex for a URL format does not match
+	// the number of groups that were expected to be found.
+	ErrGroupCountMismatch = errors.New("group count mismatch")
+	// ErrParse is matched by every ParseError using errors.Is.
+	ErrParse = errors.New("could not parse")
+	// ErrElementNotFound is returned by URL.ScrapeStrict when no element matches the selector for a field.
+	ErrElementNotFound = errors.New("element not found")
+	// ErrBodyTooLarge is returned when a response body is larger than the maximum number of bytes that can be read (see
+	// DefaultMaxBytes).
+	ErrBodyTooLarge = errors.New("response body too large")
+)
+
+// ParseError is returned when a string cannot be parsed into a value. This is either a group matched by the regex for a
+// URL format that could not be parsed by the parser for its verb, or a response body that could not be decoded.
+type ParseError struct {
+	// Value is the string that could not be parsed.
+	Value string
+	// Pattern is the regex pattern of the group that matched Value. This is empty if Value is a response body.
+	Pattern string
+	// Err is the underlying error returned by the parser.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	if e.Pattern == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("could not parse string %q using parser for %q: %s", e.Value, e.Pattern, e.Err.Error())
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// Is returns true if the target is ErrParse.
+func (e *ParseError) Is(target error) bool { return target == ErrParse }
+
+// FetchError is returned when a request to a URL could not be made, or when the response to a request could not be
+// read.
+type FetchError struct {
+	// URL is the URL that was requested.
+	URL string
+	// StatusCode is the status code of the response. This is 0 if no response was received.
+	StatusCode int
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *FetchError) Error() string { return e.Err.Error() }
+
+func (e *FetchError) Unwrap() error { return e.Err }
+
+// fetchError creates a FetchError for the given URL and status code (0 if no response was received), wrapping the given
+// error with the given message.
+func fetchError(err error, url string, statusCode int, format string, args ...any) error {
+	return &FetchError{URL: url, StatusCode: statusCode, Err: errors.Wrapf(err, format, args...)}
+}
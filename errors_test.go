@@ -0,0 +1,71 @@
+package urlfmt
+
+import (
+	"github.com/pkg/errors"
+	"math"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	const (
+		SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+		BoolPage     URL = "%s://example.com/%t"
+	)
+
+	t.Run("ErrNoMatch", func(t *testing.T) {
+		_, err := SteamAppPage.ExtractArgsErr("https://store.steampowered.com/app/Human_Fall_Flat")
+		if !errors.Is(err, ErrNoMatch) {
+			t.Errorf("expected %v to be ErrNoMatch", err)
+		}
+		var appID int
+		if err = SteamAppPage.Unmarshal("https://sokpop.itch.io/ballspell", &appID); !errors.Is(err, ErrNoMatch) {
+			t.Errorf("expected %v to be ErrNoMatch", err)
+		}
+	})
+
+	t.Run("ErrParse", func(t *testing.T) {
+		_, err := SteamAppPage.ExtractArgsErr("https://store.steampowered.com/app/" + strconv.FormatUint(math.MaxUint64, 10))
+		if !errors.Is(err, ErrParse) {
+			t.Errorf("expected %v to be ErrParse", err)
+		}
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("expected %v to be a *ParseError", err)
+		}
+		if parseErr.Value != strconv.FormatUint(math.MaxUint64, 10) || parseErr.Pattern != string(base10VerbRegexPattern) {
+			t.Errorf("unexpected ParseError fields: %+v", parseErr)
+		}
+		if !errors.Is(err, strconv.ErrRange) {
+			t.Errorf("expected %v to wrap strconv.ErrRange", err)
+		}
+		if _, err = BoolPage.ExtractArgsErr("https://example.com/true"); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("FetchError", func(t *testing.T) {
+		_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("not JSON"))
+		}))
+		const Page URL = "%s://%s/app/%d"
+
+		_, _, err := Page.JSON(nil, "localhost:0", 477160)
+		var fetchErr *FetchError
+		if !errors.As(err, &fetchErr) {
+			t.Fatalf("expected %v to be a *FetchError", err)
+		}
+		if fetchErr.URL != "https://localhost:0/app/477160" || fetchErr.StatusCode != 0 {
+			t.Errorf("unexpected FetchError fields: %+v", fetchErr)
+		}
+
+		_, _, err = Page.JSON(nil, host, 477160)
+		if !errors.Is(err, ErrParse) {
+			t.Errorf("expected %v to be ErrParse", err)
+		}
+		if errors.As(err, &fetchErr) {
+			t.Errorf("did not expect %v to be a *FetchError", err)
+		}
+	})
+}
@@ -3,12 +3,14 @@ module github.com/andygello555/url-fmt
 go 1.19
 
 require (
+	github.com/PuerkitoBio/goquery v1.8.1
 	github.com/anaskhan96/soup v1.2.5
 	github.com/andygello555/agem v1.0.2
 	github.com/pkg/errors v0.9.1
+	golang.org/x/net v0.7.0
 )
 
 require (
-	golang.org/x/net v0.0.0-20200114155413-6afb5195e5aa // indirect
-	golang.org/x/text v0.3.0 // indirect
+	github.com/andybalholm/cascadia v1.3.1 // indirect
+	golang.org/x/text v0.7.0 // indirect
 )
@@ -0,0 +1,23 @@
+package urlfmt
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTLSServer starts a new httptest.Server using TLS and swaps out http.DefaultTransport for the server's transport
+// for the duration of the test. This allows URLs that are filled with the "https" protocol to be fetched from the
+// server. The host (and port) of the server is returned so that it can be used as an arg to a URL format.
+func newTLSServer(t *testing.T, handler http.Handler) (server *httptest.Server, host string) {
+	t.Helper()
+	server = httptest.NewTLSServer(handler)
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+	t.Cleanup(func() {
+		http.DefaultTransport = defaultTransport
+		server.Close()
+	})
+	return server, strings.TrimPrefix(server.URL, "https://")
+}
@@ -0,0 +1,91 @@
+package urlfmt
+
+import (
+	"golang.org/x/net/idna"
+	"strings"
+)
+
+// splitAuthority splits the given authority of a URL, or URL format, into its userinfo (including the trailing "@"),
+// host, and port (including the leading ":"). The userinfo and port are empty if the authority does not have them.
+func splitAuthority(authority string) (userinfo, host, port string) {
+	host = authority
+	if at := strings.LastIndexByte(host, '@'); at >= 0 {
+		userinfo, host = host[:at+1], host[at+1:]
+	}
+	if colon := strings.LastIndexByte(host, ':'); colon >= 0 && !strings.Contains(host[colon:], "]") {
+		host, port = host[:colon], host[colon:]
+	}
+	return
+}
+
+// asciiHost returns a copy of the given URL with its host converted to its ASCII (punycode) form, e.g.
+// "https://müller.example/app/1" becomes "https://xn--mller-kva.example/app/1". The host is also lower-cased. Any
+// userinfo and port are left untouched. If the host is not a valid internationalised domain name then the URL is
+// returned with only its scheme and host lower-cased (see foldHost).
+func asciiHost(url string) string {
+	end := hostEnd(url)
+	if end < 0 {
+		return url
+	}
+	start := strings.Index(url, "//") + len("//")
+	userinfo, host, port := splitAuthority(url[start:end])
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return foldHost(url)
+	}
+	return strings.ToLower(url[:start]) + userinfo + ascii + port + url[end:]
+}
+
+// asciiHost returns a copy of the URL format with each label of its host that contains no verbs converted to its ASCII
+// (punycode) form, and lower-cased. Labels that contain verbs are left untouched, so a verb should fill an entire label
+// of an internationalised domain name, e.g. "%s://%s.müller.example", rather than part of one.
+func (u URL) asciiHost() URL {
+	format := string(u.foldHost())
+	end := hostEnd(format)
+	if end < 0 {
+		return URL(format)
+	}
+	start := strings.Index(format, "//") + len("//")
+	userinfo, host, port := splitAuthority(format[start:end])
+
+	labels := strings.Split(host, ".")
+	for i, label := range labels {
+		if strings.Contains(label, "%") {
+			continue
+		}
+		if ascii, err := idna.ToASCII(label); err == nil {
+			labels[i] = ascii
+		}
+	}
+	return URL(format[:start] + userinfo + strings.Join(labels, ".") + port + format[end:])
+}
+
+// MatchIDN is the same as MatchFold, except that internationalised domain names within both the URL format and the
+// given URL are compared in their ASCII (punycode) form. This allows a URL format containing a Unicode host, e.g.
+// "%s://müller.example/app/%d", to match a URL containing the punycode host, e.g.
+// "https://xn--mller-kva.example/app/1", and vice versa.
+func (u URL) MatchIDN(url string) bool {
+	return u.asciiHost().Match(asciiHost(url))
+}
+
+// ExtractArgsIDN is the same as ExtractArgsErr, except that internationalised domain names are compared in their ASCII
+// (punycode) form (see MatchIDN). Any string args extracted from the host are returned in their ASCII form, unless
+// unicode is true, in which case they are converted back to their Unicode form, e.g. "xn--mller-kva" becomes "müller".
+func (u URL) ExtractArgsIDN(url string, unicode bool) (args []any, err error) {
+	if args, err = u.asciiHost().ExtractArgsErr(asciiHost(url)); err != nil || !unicode {
+		return
+	}
+
+	end := hostEnd(u.String())
+	for i, verb := range u.Verbs() {
+		if verb.Offset >= end {
+			break
+		}
+		if arg, ok := args[i].(string); ok {
+			if converted, err := idna.ToUnicode(arg); err == nil {
+				args[i] = converted
+			}
+		}
+	}
+	return args, nil
+}
@@ -0,0 +1,47 @@
+package urlfmt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestURL_MatchIDN(t *testing.T) {
+	for _, test := range []struct {
+		u     URL
+		url   string
+		args  []any
+		match bool
+	}{
+		{"%s://müller.example/app/%d", "https://xn--mller-kva.example/app/1", []any{int64(1)}, true},
+		{"%s://xn--mller-kva.example/app/%d", "https://müller.example/app/2", []any{int64(2)}, true},
+		{"%s://müller.example/app/%d", "https://MÜLLER.example/app/3", []any{int64(3)}, true},
+		{"%s://%s@müller.example:%d/app/%d", "https://user@MÜLLER.example:8443/app/4", []any{"user", int64(8443), int64(4)}, true},
+		{"%s://müller.example/app/%d", "https://mueller.example/app/5", nil, false},
+		{"%s://%s.bücher.example/%s", "https://xn--mnchen-3ya.xn--bcher-kva.example/isbn", []any{"xn--mnchen-3ya", "isbn"}, true},
+	} {
+		if matched := test.u.MatchIDN(test.url); matched != test.match {
+			t.Errorf("expected %s to match %q: %t, got %t", test.u, test.url, test.match, matched)
+		}
+		if test.u.Match(test.url) {
+			t.Errorf("expected %s not to match %q without IDN normalisation", test.u, test.url)
+		}
+		args, err := test.u.ExtractArgsIDN(test.url, false)
+		switch {
+		case test.match && err != nil:
+			t.Errorf("unexpected error for %q: %v", test.url, err)
+		case test.match && !reflect.DeepEqual(args, test.args):
+			t.Errorf("expected %v to be extracted from %q, got %v", test.args, test.url, args)
+		case !test.match && err == nil:
+			t.Errorf("expected an error for %q, got %v", test.url, args)
+		}
+	}
+
+	const CityBooks URL = "%s://%s.bücher.example/%s"
+	args, err := CityBooks.ExtractArgsIDN("https://münchen.bücher.example/isbn", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(args, []any{"münchen", "isbn"}) {
+		t.Errorf("expected host args in their Unicode form, got %v", args)
+	}
+}
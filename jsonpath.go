@@ -0,0 +1,117 @@
+package urlfmt
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// pathStep is a single step of a path given to GetPath, which is either a key of a map, or an index of a slice.
+type pathStep struct {
+	key   string
+	index int
+	isKey bool
+}
+
+// parsePath parses the given dotted and bracketed path into its steps, e.g. "reviews[0].author" is parsed into the key
+// "reviews", the index 0, and the key "author". False is returned if the path is malformed.
+func parsePath(path string) (steps []pathStep, ok bool) {
+	if path == "" {
+		return nil, false
+	}
+
+	steps = make([]pathStep, 0)
+	for i := 0; i < len(path); {
+		if path[i] == '[' {
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, false
+			}
+			index, err := strconv.Atoi(path[i+1 : i+end])
+			if err != nil || index < 0 {
+				return nil, false
+			}
+			steps = append(steps, pathStep{index: index})
+			i += end + 1
+		} else {
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			if end == 0 {
+				return nil, false
+			}
+			steps = append(steps, pathStep{key: path[i : i+end], isKey: true})
+			i += end
+		}
+
+		// Each step must be followed by the end of the path, an index, or a "." and then a key
+		switch {
+		case i == len(path) || path[i] == '[':
+		case path[i] == '.' && i+1 < len(path) && path[i+1] != '.' && path[i+1] != '[':
+			i++
+		default:
+			return nil, false
+		}
+	}
+	return steps, true
+}
+
+// GetPath returns the value at the given path within the given map, which is usually the JSON returned by URL.JSON.
+// The path is a dotted list of keys, where any key can be followed by indices in square brackets to index into a
+// slice, e.g.
+//
+//	total, ok := GetPath(jsonBody, "query_summary.total_reviews")
+//	author, ok := GetPath(jsonBody, "reviews[0].author.steamid")
+//
+// False is returned if any key or index along the path does not exist, if a key is used on a value that is not a
+// map[string]any, if an index is used on a value that is not a []any, or if the path is malformed. Keys containing
+// "." or "[" cannot be reached.
+func GetPath(m map[string]any, path string) (any, bool) {
+	steps, ok := parsePath(path)
+	if !ok {
+		return nil, false
+	}
+
+	var current any = m
+	for _, step := range steps {
+		if step.isKey {
+			object, ok := current.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if current, ok = object[step.key]; !ok {
+				return nil, false
+			}
+			continue
+		}
+
+		array, ok := current.([]any)
+		if !ok || step.index >= len(array) {
+			return nil, false
+		}
+		current = array[step.index]
+	}
+	return current, true
+}
+
+// GetPathAs is the same as GetPath, except that the value is converted to the given type. Numeric values are converted
+// between numeric types (see URL.Unmarshal), and the float64 that encoding/json produces for every JSON number can be
+// retrieved as an integer type if it is a whole number. False is returned if the path does not exist, or if the value
+// cannot be converted.
+//
+//	total, ok := GetPathAs[int](jsonBody, "query_summary.total_reviews")
+func GetPathAs[T any](m map[string]any, path string) (value T, ok bool) {
+	var raw any
+	if raw, ok = GetPath(m, path); !ok || raw == nil {
+		return value, ok
+	}
+	if err := assignArg(&value, raw); err != nil {
+		// encoding/json decodes every JSON number into a float64, so whole numbers are retried as integers
+		f, isFloat := raw.(float64)
+		if !isFloat || f != math.Trunc(f) || assignArg(&value, int64(f)) != nil {
+			return value, false
+		}
+	}
+	return value, true
+}
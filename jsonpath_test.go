@@ -0,0 +1,66 @@
+package urlfmt
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGetPath(t *testing.T) {
+	var jsonBody map[string]any
+	if err := json.Unmarshal([]byte(`{
+		"success": 1,
+		"query_summary": {"num_reviews": 2, "review_score_desc": "Very Positive"},
+		"reviews": [
+			{"author": {"steamid": "76561198000000000", "num_games_owned": 120}, "voted_up": true},
+			{"author": {"steamid": "76561198000000001"}, "voted_up": false}
+		],
+		"matrix": [[1, 2], [3, 4]],
+		"empty": null
+	}`), &jsonBody); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, test := range []struct {
+		path     string
+		expected any
+		ok       bool
+	}{
+		{"success", float64(1), true},
+		{"query_summary.review_score_desc", "Very Positive", true},
+		{"reviews[0].author.steamid", "76561198000000000", true},
+		{"reviews[1].voted_up", false, true},
+		{"matrix[1][0]", float64(3), true},
+		{"matrix[1]", []any{float64(3), float64(4)}, true},
+		{"empty", nil, true},
+		{"missing", nil, false},
+		{"query_summary.missing", nil, false},
+		{"reviews[2].author", nil, false},
+		{"reviews.author", nil, false},
+		{"success[0]", nil, false},
+		{"success.value", nil, false},
+		{"reviews[-1]", nil, false},
+		{"reviews[0", nil, false},
+		{"reviews[a]", nil, false},
+		{"reviews[0]author", nil, false},
+		{"query_summary..num_reviews", nil, false},
+	} {
+		actual, ok := GetPath(jsonBody, test.path)
+		if ok != test.ok || !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%s: expected %v (%t), got %v (%t)", test.path, test.expected, test.ok, actual, ok)
+		}
+	}
+
+	if numGames, ok := GetPathAs[int](jsonBody, "reviews[0].author.num_games_owned"); !ok || numGames != 120 {
+		t.Errorf("expected 120, got %d (%t)", numGames, ok)
+	}
+	if steamID, ok := GetPathAs[string](jsonBody, "reviews[1].author.steamid"); !ok || steamID != "76561198000000001" {
+		t.Errorf("expected 76561198000000001, got %q (%t)", steamID, ok)
+	}
+	if _, ok := GetPathAs[int](jsonBody, "query_summary.review_score_desc"); ok {
+		t.Errorf("expected a string not to be converted to an int")
+	}
+	if _, ok := GetPathAs[bool](jsonBody, "reviews[5].voted_up"); ok {
+		t.Errorf("expected a missing path not to be ok")
+	}
+}
@@ -0,0 +1,123 @@
+package urlfmt
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// namedPlaceholderPattern matches a named placeholder within a NamedURL. The first group is the name of the placeholder
+// and the second (optional) group is the string interpolation verb that the placeholder should be resolved to.
+var namedPlaceholderPattern = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]*)(?::(#?[a-zA-Z]))?}`)
+
+// NamedURL is a URL format that references its arguments by name rather than by position. Placeholders are written as
+// "{name}" or "{name:verb}", where verb is any of the string interpolation verbs understood by URL. If no verb is given
+// then the placeholder is treated as a string verb ("%s"). The protocol is given in the same way as for URL, i.e. as a
+// "%s://" prefix, e.g.
+//
+//	"%s://store.steampowered.com/appreviews/{appID:d}?cursor={cursor}&num_per_page={n:d}"
+//
+// Internally, a NamedURL is resolved to a positional URL so that all the verb-to-regex translation and parsing is
+// shared.
+type NamedURL string
+
+// URL resolves the NamedURL to a positional URL by replacing each named placeholder with its string interpolation
+// verb. The names of the placeholders are also returned in the order in which they appear in the format.
+func (n NamedURL) URL() (u URL, names []string) {
+	names = make([]string, 0)
+	u = URL(namedPlaceholderPattern.ReplaceAllStringFunc(string(n), func(s string) string {
+		groups := namedPlaceholderPattern.FindStringSubmatch(s)
+		names = append(names, groups[1])
+		verb := string(stringVerb)
+		if groups[2] != "" {
+			verb = groups[2]
+		}
+		return "%" + verb
+	}))
+	return
+}
+
+// Names returns the names of the placeholders in the order in which they appear in the format.
+func (n NamedURL) Names() []string {
+	_, names := n.URL()
+	return names
+}
+
+// String returns the un-formatted positional URL with the protocol. See URL.String for more info.
+func (n NamedURL) String() string {
+	u, _ := n.URL()
+	return u.String()
+}
+
+// Fill will apply string interpolation to the NamedURL using the given mapping of placeholder names to values. If a
+// placeholder's name does not exist in the mapping then nil will be used in its place.
+func (n NamedURL) Fill(args map[string]any) string {
+	u, names := n.URL()
+	positional := make([]any, len(names))
+	for i, name := range names {
+		positional[i] = args[name]
+	}
+	return u.Fill(positional...)
+}
+
+// Regex converts the NamedURL to a regex. See URL.Regex for more info.
+func (n NamedURL) Regex() *regexp.Regexp {
+	u, _ := n.URL()
+	return u.Regex()
+}
+
+// Match the given URL with a NamedURL to check if they are the same format. See URL.Match for more info.
+func (n NamedURL) Match(url string) bool {
+	u, _ := n.URL()
+	return u.Match(url)
+}
+
+// ExtractArgs extracts the arguments from the given URL and returns them keyed by their placeholder names. The values
+// are parsed in the same way as URL.ExtractArgs. Unlike ExtractArgsMap, which returns an error, if a name is used by
+// multiple placeholders then ExtractArgs uses the value of the last of those placeholders.
+func (n NamedURL) ExtractArgs(url string) map[string]any {
+	u, names := n.URL()
+	args := u.ExtractArgs(url)
+	named := make(map[string]any, len(names))
+	for i, name := range names {
+		named[name] = args[i]
+	}
+	return named
+}
+
+// ExtractArgsMap is the same as ExtractArgs, except that an error is returned instead of panicking, and an error is
+// returned if a name is used by multiple placeholders, rather than silently using the value of the last placeholder.
+func (n NamedURL) ExtractArgsMap(url string) (map[string]any, error) {
+	u, names := n.URL()
+	named := make(map[string]any, len(names))
+	for _, name := range names {
+		if _, ok := named[name]; ok {
+			return nil, fmt.Errorf("the placeholder name %q is used more than once in %s", name, string(n))
+		}
+		named[name] = nil
+	}
+
+	args, err := u.ExtractArgsErr(url)
+	if err != nil {
+		return nil, err
+	}
+	for i, name := range names {
+		named[name] = args[i]
+	}
+	return named, nil
+}
+
+// MatchExtract is the same as URL.MatchExtract, except that the args are keyed by their placeholder names (see
+// ExtractArgsMap). If the URL does not match, its args cannot be parsed, or a name is used by multiple placeholders,
+// then nil and false are returned.
+func (n NamedURL) MatchExtract(url string) (map[string]any, bool) {
+	named, err := n.ExtractArgsMap(url)
+	if err != nil {
+		return nil, false
+	}
+	return named, true
+}
+
+// Standardise will first extract the named args from the given URL then Fill the NamedURL with those args.
+func (n NamedURL) Standardise(url string) string {
+	return n.Fill(n.ExtractArgs(url))
+}
@@ -0,0 +1,81 @@
+package urlfmt
+
+import "fmt"
+
+func ExampleNamedURL_Fill() {
+	const SteamAppReviews NamedURL = "%s://store.steampowered.com/appreviews/{appID:d}?json=1&cursor={cursor}&num_per_page={n:d}"
+
+	fmt.Println(SteamAppReviews.Names())
+	fmt.Println(SteamAppReviews.Fill(map[string]any{"appID": 477160, "cursor": "*", "n": 20}))
+	// Output:
+	// [appID cursor n]
+	// https://store.steampowered.com/appreviews/477160?json=1&cursor=*&num_per_page=20
+}
+
+func ExampleNamedURL_ExtractArgs() {
+	const (
+		SteamAppPage   NamedURL = "%s://store.steampowered.com/app/{appID:d}"
+		ItchIOGamePage NamedURL = "%s://{developer}.itch.io/{game}"
+	)
+
+	fmt.Println(SteamAppPage.Regex())
+	fmt.Println(SteamAppPage.ExtractArgs("https://store.steampowered.com/app/477160/Human_Fall_Flat/"))
+	fmt.Println(ItchIOGamePage.Regex())
+	fmt.Println(ItchIOGamePage.ExtractArgs("https://hempuli.itch.io/baba-files-taxes"))
+	fmt.Println(NamedURL("%s://{game}.itch.io/{game}").ExtractArgs("https://baba.itch.io/keke"))
+	// Output:
+	// (?:https?:)?//store\.steampowered\.com/app/([+-]?\d+)
+	// map[appID:477160]
+	// (?:https?:)?//((?:[a-zA-Z0-9-._~]|%[0-9A-Fa-f]{2})+)\.itch\.io/((?:[a-zA-Z0-9-._~]|%[0-9A-Fa-f]{2})+)
+	// map[developer:hempuli game:baba-files-taxes]
+	// map[game:keke]
+}
+
+func ExampleNamedURL_Match() {
+	const SteamAppPage NamedURL = "%s://store.steampowered.com/app/{appID:d}/reviews"
+
+	// The fragment is ignored, as the URL format does not contain one, so the URL within it is not matched
+	for _, url := range []string{
+		"https://store.steampowered.com/app/477160/reviews#top",
+		"https://hempuli.itch.io/baba#https://store.steampowered.com/app/477160/reviews",
+	} {
+		u, _ := SteamAppPage.URL()
+		fmt.Println(SteamAppPage.Match(url), u.Match(url))
+	}
+	// Output:
+	// true true
+	// false false
+}
+
+func ExampleNamedURL_ExtractArgsMap() {
+	const (
+		SteamAppPage   NamedURL = "%s://store.steampowered.com/app/{appID:d}"
+		ItchIOGamePage URL      = "%s://%s.itch.io/%s"
+		Mirrored       NamedURL = "%s://{game}.itch.io/{game}"
+	)
+
+	fmt.Println(SteamAppPage.ExtractArgsMap("https://store.steampowered.com/app/477160"))
+	fmt.Println(ItchIOGamePage.ExtractArgsMap("https://hempuli.itch.io/baba-files-taxes"))
+	fmt.Println(Mirrored.ExtractArgsMap("https://baba.itch.io/baba"))
+	// Output:
+	// map[appID:477160] <nil>
+	// map[arg0:hempuli arg1:baba-files-taxes] <nil>
+	// map[] the placeholder name "game" is used more than once in %s://{game}.itch.io/{game}
+}
+
+func ExampleNamedURL_MatchExtract() {
+	const (
+		SteamAppPage   NamedURL = "%s://store.steampowered.com/app/{appID:d}"
+		ItchIOGamePage URL      = "%s://%s.itch.io/%s"
+	)
+
+	fmt.Println(SteamAppPage.MatchExtract("https://store.steampowered.com/app/477160"))
+	fmt.Println(SteamAppPage.MatchExtract("https://hempuli.itch.io/baba-files-taxes"))
+	fmt.Println(ItchIOGamePage.MatchExtract("https://hempuli.itch.io/baba-files-taxes"))
+	fmt.Println(ItchIOGamePage.MatchExtract("https://store.steampowered.com/app/477160"))
+	// Output:
+	// map[appID:477160] true
+	// map[] false
+	// map[arg0:hempuli arg1:baba-files-taxes] true
+	// map[] false
+}
@@ -0,0 +1,61 @@
+package urlfmt
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Observer is notified after each request made by Soup, JSON, and the functions and methods built on top of them (e.g.
+// SoupWithClient, JSONOpts, JSONInto, Session.Soup), has completed. This allows metrics and tracing, such as Prometheus
+// or OpenTelemetry, to be wired in at a single point rather than at every call site. See SetObserver.
+type Observer interface {
+	// RequestDone is called once the response body to the given URL has been read and closed, or once the request has
+	// failed. The resp may be nil if no response was received, and err is the error that will be returned to the
+	// caller, if any. The duration covers the entire request, including reading the response body.
+	RequestDone(url string, resp *http.Response, dur time.Duration, err error)
+}
+
+// ObserverFunc is an adapter to allow the use of an ordinary function as an Observer.
+type ObserverFunc func(url string, resp *http.Response, dur time.Duration, err error)
+
+// RequestDone calls f(url, resp, dur, err).
+func (f ObserverFunc) RequestDone(url string, resp *http.Response, dur time.Duration, err error) {
+	f(url, resp, dur, err)
+}
+
+// noopObserver is the default Observer, which does nothing.
+type noopObserver struct{}
+
+func (noopObserver) RequestDone(string, *http.Response, time.Duration, error) {}
+
+var (
+	observerMu sync.RWMutex
+	observer   Observer = noopObserver{}
+)
+
+// SetObserver sets the package level Observer that is notified after each request. Setting a nil Observer restores the
+// default, which does nothing. The Observer is called synchronously on the goroutine that made the request, before the
+// request returns, so a slow Observer will slow down every request. Observers that do expensive work, such as
+// exporting over the network, should hand off to another goroutine. The Observer may be called concurrently.
+//
+// Every request made by the package is observed, including those made by Head, Exists, JSONDecode, and Stream. As the
+// caller reads the response body returned by JSONStream, its request is observed once the response headers have been
+// received, rather than once the response body has been read and closed.
+func SetObserver(o Observer) {
+	if o == nil {
+		o = noopObserver{}
+	}
+	observerMu.Lock()
+	defer observerMu.Unlock()
+	observer = o
+}
+
+// observe notifies the package level Observer (see SetObserver) that the request to the given URL, which started at
+// the given time, has completed.
+func observe(url string, resp *http.Response, start time.Time, err error) {
+	observerMu.RLock()
+	o := observer
+	observerMu.RUnlock()
+	o.RequestDone(url, resp, time.Since(start), err)
+}
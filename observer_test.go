@@ -0,0 +1,106 @@
+package urlfmt
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSetObserver(t *testing.T) {
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte(`{"short":"and stout"}`))
+	}))
+
+	type observation struct {
+		url        string
+		statusCode int
+		dur        time.Duration
+		err        error
+	}
+	var observations []observation
+	SetObserver(ObserverFunc(func(url string, resp *http.Response, dur time.Duration, err error) {
+		o := observation{url: url, dur: dur, err: err}
+		if resp != nil {
+			o.statusCode = resp.StatusCode
+		}
+		observations = append(observations, o)
+	}))
+	t.Cleanup(func() { SetObserver(nil) })
+
+	const Teapot URL = "%s://%s/teapot/%d"
+	if _, _, err := Teapot.JSON(nil, host, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(observations) != 1 {
+		t.Fatalf("expected 1 observation, got %d", len(observations))
+	}
+	o := observations[0]
+	if o.url != Teapot.Fill(host, 1) {
+		t.Errorf("expected the observed URL to be %q, got %q", Teapot.Fill(host, 1), o.url)
+	}
+	if o.statusCode != http.StatusTeapot {
+		t.Errorf("expected the observed status to be %d, got %d", http.StatusTeapot, o.statusCode)
+	}
+	if o.dur <= 0 {
+		t.Errorf("expected a non-zero duration, got %v", o.dur)
+	}
+	if o.err != nil {
+		t.Errorf("unexpected observed error: %v", o.err)
+	}
+
+	SetObserver(nil)
+	if _, _, err := Teapot.JSON(nil, host, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(observations) != 1 {
+		t.Errorf("expected no observations after resetting the observer, got %d", len(observations))
+	}
+}
+
+func TestSetObserver_allRequests(t *testing.T) {
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead && r.URL.Path == "/nohead" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+
+	var statusCodes []int
+	SetObserver(ObserverFunc(func(url string, resp *http.Response, dur time.Duration, err error) {
+		if err != nil {
+			t.Errorf("unexpected observed error for %s: %v", url, err)
+		}
+		statusCodes = append(statusCodes, resp.StatusCode)
+	}))
+	t.Cleanup(func() { SetObserver(nil) })
+
+	const Page URL = "%s://%s/%s"
+	for _, test := range []struct {
+		name        string
+		request     func() error
+		statusCodes []int
+	}{
+		{"Head", func() error { _, err := Page.Head(nil, host, "head"); return err }, []int{http.StatusOK}},
+		{"Exists", func() error { _, err := Page.Exists(host, "nohead"); return err }, []int{http.StatusMethodNotAllowed, http.StatusOK}},
+		{"JSONDecode", func() error { _, _, err := Page.JSONDecode(nil, host, "decode"); return err }, []int{http.StatusOK}},
+		{"JSONStream", func() error {
+			_, resp, err := Page.JSONStream(nil, host, "stream")
+			if err == nil {
+				err = resp.Body.Close()
+			}
+			return err
+		}, []int{http.StatusOK}},
+	} {
+		statusCodes = nil
+		if err := test.request(); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		if fmt.Sprint(statusCodes) != fmt.Sprint(test.statusCodes) {
+			t.Errorf("%s: expected the observed statuses to be %v, got %v", test.name, test.statusCodes, statusCodes)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package urlfmt
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// optionalOpen marks the start of an optional section within a URL format.
+	optionalOpen = "%{"
+	// optionalClose marks the end of an optional section within a URL format.
+	optionalClose = "%}"
+)
+
+// optionalPattern matches an optional section within a URL format. The first group is the contents of the section.
+// Optional sections cannot be nested. An optional section is omitted by Fill when all the args for the verbs within it
+// are nil, and the regex produced by Regex will match URLs both with and without the section, e.g.
+//
+//	"%s://localhost%{:%d%}/app/%d"
+//
+// Will match both "http://localhost/app/5" and "http://localhost:8080/app/5". When the section is absent, the args for
+// the verbs within it will be extracted as nil.
+//
+// Optional sections that begin with a query delimiter ("?" or "&") are optional query parameters, which should be
+// placed at the end of the URL format. The delimiter of each optional query parameter is switched by Fill, so that the
+// first query parameter that is present always begins with "?", and the rest with "&". Likewise, the regex produced by
+// Regex will match optional query parameters beginning with either delimiter, e.g.
+//
+//	"%s://store.steampowered.com/search%{?page=%d%}%{&sort=%s%}"
+//
+// Will match "https://store.steampowered.com/search", "https://store.steampowered.com/search?page=2", and
+// "https://store.steampowered.com/search?sort=price".
+var optionalPattern = regexp.MustCompile(`%\{(.*?)%}`)
+
+// fillOptional removes each optional section within the given format for which all the corresponding args are nil (or
+// missing), along with those args. The markers of the remaining optional sections are removed, so that the returned
+// format and args can be passed to fmt.Sprintf. The given args should include the protocol.
+func fillOptional(format string, args []any) (string, []any) {
+	if !strings.Contains(format, optionalOpen) {
+		return format, args
+	}
+
+	filled := make([]any, 0, len(args))
+	var b strings.Builder
+	last, token := 0, 0
+	for _, loc := range optionalPattern.FindAllStringSubmatchIndex(format, -1) {
+		before := len(findTokens(format[last:loc[0]]))
+		inside := len(findTokens(format[loc[2]:loc[3]]))
+		b.WriteString(format[last:loc[0]])
+		filled = append(filled, argsBetween(args, token, token+before)...)
+		token += before
+
+		present := false
+		for _, arg := range argsBetween(args, token, token+inside) {
+			present = present || arg != nil
+		}
+		if present {
+			section := format[loc[2]:loc[3]]
+			if queryDelimiter(section) {
+				// Switch the delimiter depending on whether the query has already begun
+				delimiter := "?"
+				if strings.Contains(b.String(), "?") {
+					delimiter = "&"
+				}
+				section = delimiter + section[1:]
+			}
+			b.WriteString(section)
+			filled = append(filled, argsBetween(args, token, token+inside)...)
+		}
+		token += inside
+		last = loc[1]
+	}
+	b.WriteString(format[last:])
+	filled = append(filled, argsBetween(args, token, len(args))...)
+	return b.String(), filled
+}
+
+// queryDelimiter returns whether the given contents of an optional section begin with a query delimiter, i.e. the
+// section is an optional query parameter.
+func queryDelimiter(section string) bool {
+	return strings.HasPrefix(section, "?") || strings.HasPrefix(section, "&")
+}
+
+// argsBetween returns args[start:end], clamped to the bounds of args.
+func argsBetween(args []any, start int, end int) []any {
+	if start > len(args) {
+		start = len(args)
+	}
+	if end > len(args) {
+		end = len(args)
+	}
+	return args[start:end]
+}
+
+// quoteLiteral escapes the given literal text from a URL format so that it is matched literally by a regex, except for
+// the markers of optional sections, which are converted to an optional non-capturing group, and escaped percent signs
+// ("%%"), which are converted to a single literal "%". The delimiter at the beginning of an optional query parameter
+// (see optionalPattern) is converted to a character class matching either delimiter.
+func quoteLiteral(literal string) string {
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(literal)-1; i++ {
+		var replacement string
+		skip := 2
+		switch literal[i : i+2] {
+		case "%%":
+			replacement = "%"
+		case optionalOpen:
+			replacement = "(?:"
+			if queryDelimiter(literal[i+2:]) {
+				replacement += "[?&]"
+				skip++
+			}
+		case optionalClose:
+			replacement = ")?"
+		default:
+			continue
+		}
+		b.WriteString(regexp.QuoteMeta(literal[last:i]))
+		b.WriteString(replacement)
+		i += skip - 1
+		last = i + 1
+	}
+	b.WriteString(regexp.QuoteMeta(literal[last:]))
+	return b.String()
+}
+
+// validateOptional checks that the optional sections within the given format are balanced and not nested.
+func validateOptional(format string) error {
+	open := -1
+	for i := 0; i < len(format)-1; i++ {
+		switch format[i : i+2] {
+		case "%%":
+			i++
+		case optionalOpen:
+			if open >= 0 {
+				return fmt.Errorf("%s contains an optional section at offset %d that is nested within the optional section at offset %d", format, i, open)
+			}
+			open = i
+			i++
+		case optionalClose:
+			if open < 0 {
+				return fmt.Errorf("%s contains the end of an optional section at offset %d without a start", format, i)
+			}
+			open = -1
+			i++
+		}
+	}
+	if open >= 0 {
+		return fmt.Errorf("%s contains an optional section at offset %d that is never closed", format, open)
+	}
+	return nil
+}
+
+// optional returns whether the given offset within the given format is within an optional section.
+func optional(format string, offset int) bool {
+	for _, loc := range optionalPattern.FindAllStringIndex(format, -1) {
+		if loc[0] < offset && offset < loc[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package urlfmt
+
+import (
+	"fmt"
+	"testing"
+)
+
+func ExampleURL_Fill_optional() {
+	const LocalAppPage URL = "%s://localhost%{:%d%}/app/%d"
+	fmt.Println(LocalAppPage.Fill(8080, 5))
+	fmt.Println(LocalAppPage.Fill(nil, 5))
+	fmt.Println(LocalAppPage.Regex())
+	// Output:
+	// https://localhost:8080/app/5
+	// https://localhost/app/5
+	// (?:https?:)?//localhost(?::([+-]?\d+))?/app/([+-]?\d+)
+}
+
+func TestURL_optionalPort(t *testing.T) {
+	const LocalAppPage URL = "%s://localhost%{:%d%}/app/%d"
+	for _, test := range []struct {
+		url      string
+		expected []any
+	}{
+		{"http://localhost/app/5", []any{nil, int64(5)}},
+		{"http://localhost:8080/app/5", []any{int64(8080), int64(5)}},
+		{"https://localhost:443/app/477160/", []any{int64(443), int64(477160)}},
+	} {
+		t.Run(test.url, func(t *testing.T) {
+			args, err := LocalAppPage.ExtractArgsErr(test.url)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fmt.Sprintf("%#v", args) != fmt.Sprintf("%#v", test.expected) {
+				t.Errorf("expected %#v, got %#v", test.expected, args)
+			}
+			if standardised := LocalAppPage.Standardise(test.url); !LocalAppPage.Match(standardised) {
+				t.Errorf("expected the standardised URL %q to match", standardised)
+			}
+		})
+	}
+
+	if LocalAppPage.Match("http://localhost:port/app/5") {
+		t.Errorf("expected a non-numeric port not to match")
+	}
+	if verbs := LocalAppPage.Verbs(); len(verbs) != 2 || !verbs[0].Optional || verbs[1].Optional {
+		t.Errorf("expected only the port to be optional, got %+v", verbs)
+	}
+	if actual := LocalAppPage.Standardise("http://localhost/app/5"); actual != "https://localhost/app/5" {
+		t.Errorf("expected the absent port to be omitted, got %q", actual)
+	}
+
+	for _, invalid := range []URL{
+		"%s://localhost%{:%d/app/%d",
+		"%s://localhost:%d%}/app/%d",
+		"%s://localhost%{:%d%{/%d%}%}",
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("expected %s to be invalid", invalid)
+		}
+	}
+	if err := LocalAppPage.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestURL_optionalQuery(t *testing.T) {
+	const SteamSearch URL = "%s://store.steampowered.com/search%{?page=%d%}%{&sort=%s%}"
+	for _, test := range []struct {
+		args []any
+		url  string
+	}{
+		{[]any{nil, nil}, "https://store.steampowered.com/search"},
+		{[]any{2, nil}, "https://store.steampowered.com/search?page=2"},
+		{[]any{nil, "price"}, "https://store.steampowered.com/search?sort=price"},
+		{[]any{2, "price"}, "https://store.steampowered.com/search?page=2&sort=price"},
+	} {
+		t.Run(test.url, func(t *testing.T) {
+			if actual := SteamSearch.Fill(test.args...); actual != test.url {
+				t.Errorf("expected %q, got %q", test.url, actual)
+			}
+			if !SteamSearch.MatchExact(test.url) {
+				t.Errorf("expected %q to match %s exactly", test.url, SteamSearch)
+			}
+
+			expected := make([]any, len(test.args))
+			for i, arg := range test.args {
+				if i, ok := arg.(int); ok {
+					arg = int64(i)
+				}
+				expected[i] = arg
+			}
+			args, err := SteamSearch.ExtractArgsExact(test.url)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fmt.Sprintf("%#v", args) != fmt.Sprintf("%#v", expected) {
+				t.Errorf("expected %#v, got %#v", expected, args)
+			}
+		})
+	}
+
+	// A required query parameter followed by an optional one
+	const SteamSearchTerm URL = "%s://store.steampowered.com/search?term=%s%{&page=%d%}"
+	if actual := SteamSearchTerm.Fill("fall", 2); actual != "https://store.steampowered.com/search?term=fall&page=2" {
+		t.Errorf("unexpected filled URL %q", actual)
+	}
+	if actual := SteamSearchTerm.Fill("fall", nil); actual != "https://store.steampowered.com/search?term=fall" {
+		t.Errorf("unexpected filled URL %q", actual)
+	}
+	if args := SteamSearchTerm.ExtractArgs("https://store.steampowered.com/search?term=fall"); fmt.Sprint(args) != "[fall <nil>]" {
+		t.Errorf("expected [fall <nil>], got %v", args)
+	}
+}
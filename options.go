@@ -0,0 +1,166 @@
+package urlfmt
+
+import (
+	"context"
+	"github.com/anaskhan96/soup"
+	"net/http"
+	"time"
+)
+
+// requestConfig is the configuration for a request made by SoupOpts or JSONOpts, which is built up by applying each
+// RequestOption in turn.
+type requestConfig struct {
+	client  *http.Client
+	ctx     context.Context
+	headers http.Header
+	timeout time.Duration
+	method  string
+	// noRedirects is set by WithoutRedirects
+	noRedirects bool
+	maxBytes    int64
+}
+
+// RequestOption customises a request made by URL.SoupOpts or URL.JSONOpts. Options are applied in the order that they
+// are given, so later options override earlier ones.
+type RequestOption func(config *requestConfig)
+
+// WithClient makes the request using the given http.Client, rather than the default HTTP client (see
+// URL.SoupWithClient). If the given client is nil then the default HTTP client is used.
+func WithClient(client *http.Client) RequestOption {
+	return func(config *requestConfig) { config.client = client }
+}
+
+// WithContext makes the request using the given context.Context. Any timeout given by WithTimeout is applied on top of
+// this context.
+func WithContext(ctx context.Context) RequestOption {
+	return func(config *requestConfig) { config.ctx = ctx }
+}
+
+// WithHeader adds the given header to the request. It can be given multiple times to add multiple values for the same
+// key.
+func WithHeader(key, value string) RequestOption {
+	return func(config *requestConfig) { config.headers.Add(key, value) }
+}
+
+// WithHeaders adds each of the given headers to the request.
+func WithHeaders(headers http.Header) RequestOption {
+	return func(config *requestConfig) {
+		for key, values := range headers {
+			for _, value := range values {
+				config.headers.Add(key, value)
+			}
+		}
+	}
+}
+
+// WithTimeout makes the request time out after the given duration, instead of after DefaultTimeout. The timeout is
+// implemented using context.WithTimeout, so it cancels the request whilst the response body is being read, as well as
+// whilst connecting.
+func WithTimeout(d time.Duration) RequestOption {
+	return func(config *requestConfig) { config.timeout = d }
+}
+
+// WithMethod makes the request using the given HTTP method, rather than http.MethodGet.
+func WithMethod(method string) RequestOption {
+	return func(config *requestConfig) { config.method = method }
+}
+
+// WithMaxBytes makes the request read at most n bytes of the response body, instead of DefaultMaxBytes. If the response
+// body is larger then an error wrapping ErrBodyTooLarge is returned (see ContextWithMaxBytes).
+func WithMaxBytes(n int64) RequestOption {
+	return func(config *requestConfig) { config.maxBytes = n }
+}
+
+// WithoutRedirects stops the request from following redirects, so that the redirect response itself is returned
+// instead, e.g. to inspect its Location header rather than silently being bounced to a login or consent page. The
+// http.Client used for the request (see WithClient) is copied so that its CheckRedirect can be replaced without
+// modifying the original.
+func WithoutRedirects() RequestOption {
+	return func(config *requestConfig) { config.noRedirects = true }
+}
+
+// FinalURL returns the URL that the given response was actually fetched from, after following any redirects. This may
+// differ from the URL that was requested. An empty string is returned if the response has no http.Request.
+func FinalURL(resp *http.Response) string {
+	if resp == nil || resp.Request == nil || resp.Request.URL == nil {
+		return ""
+	}
+	return resp.Request.URL.String()
+}
+
+// newRequestConfig applies each of the given RequestOption to the default requestConfig.
+func newRequestConfig(opts ...RequestOption) *requestConfig {
+	config := &requestConfig{
+		client:  http.DefaultClient,
+		ctx:     context.Background(),
+		headers: make(http.Header),
+		method:  http.MethodGet,
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if config.client == nil {
+		config.client = http.DefaultClient
+	}
+	if config.noRedirects {
+		client := *config.client
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse }
+		config.client = &client
+	}
+	return config
+}
+
+// request creates the http.Request for the given URL format filled with the given args, according to the
+// requestConfig. The returned context.CancelFunc should be called once the response body has been read.
+func (config *requestConfig) request(u URL, args ...any) (req *http.Request, cancel context.CancelFunc, err error) {
+	if _, req, err = u.Request(config.method, nil, args...); err != nil {
+		return
+	}
+	for key, values := range config.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	ctx, cancel := config.ctx, context.CancelFunc(func() {})
+	if config.maxBytes > 0 {
+		ctx = ContextWithMaxBytes(ctx, config.maxBytes)
+	}
+	if config.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, config.timeout)
+	}
+	req = req.WithContext(ctx)
+	return
+}
+
+// SoupOpts is the same as Soup, except that the request is built by applying the given RequestOption(s), e.g.
+//
+//	SteamAppPage.SoupOpts([]any{477160}, WithHeader("Accept-Language", "en"), WithTimeout(time.Second*5))
+func (u URL) SoupOpts(args []any, opts ...RequestOption) (doc *soup.Root, resp *http.Response, err error) {
+	config := newRequestConfig(opts...)
+	var (
+		req    *http.Request
+		cancel context.CancelFunc
+	)
+	if req, cancel, err = config.request(u, args...); err != nil {
+		return
+	}
+	defer cancel()
+	return u.SoupWithClient(config.client, req)
+}
+
+// JSONOpts is the same as JSON, except that the request is built by applying the given RequestOption(s), e.g.
+//
+//	SteamAppDetails.JSONOpts([]any{477160}, WithClient(client), WithTimeout(time.Second*5))
+func (u URL) JSONOpts(args []any, opts ...RequestOption) (jsonBody map[string]any, resp *http.Response, err error) {
+	config := newRequestConfig(opts...)
+	var (
+		req    *http.Request
+		cancel context.CancelFunc
+	)
+	if req, cancel, err = config.request(u, args...); err != nil {
+		return
+	}
+	defer cancel()
+	return u.JSONWithClient(config.client, req)
+}
@@ -0,0 +1,217 @@
+package urlfmt
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestURL_JSONOpts(t *testing.T) {
+	type ctxKey struct{}
+	var outgoing *http.Request
+	client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		outgoing = req
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"success":true}`)),
+			Request:    req,
+		}, nil
+	})}
+
+	const SteamAppDetails URL = "%s://store.steampowered.com/api/appdetails?appids=%d"
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	jsonBody, _, err := SteamAppDetails.JSONOpts(
+		[]any{477160},
+		WithClient(client),
+		WithContext(ctx),
+		WithHeader("User-Agent", "url-fmt"),
+		WithHeader("Accept-Language", "en"),
+		WithTimeout(time.Minute),
+		WithMethod(http.MethodPost),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if jsonBody["success"] != true {
+		t.Errorf("unexpected JSON %v", jsonBody)
+	}
+
+	if outgoing == nil {
+		t.Fatalf("expected the request to be made using the given client")
+	}
+	if actual := outgoing.URL.String(); actual != SteamAppDetails.Fill(477160) {
+		t.Errorf("expected request to %q, got %q", SteamAppDetails.Fill(477160), actual)
+	}
+	if outgoing.Method != http.MethodPost {
+		t.Errorf("expected method %s, got %s", http.MethodPost, outgoing.Method)
+	}
+	if outgoing.Header.Get("User-Agent") != "url-fmt" || outgoing.Header.Get("Accept-Language") != "en" {
+		t.Errorf("expected headers to be applied, got %v", outgoing.Header)
+	}
+	if outgoing.Context().Value(ctxKey{}) != "value" {
+		t.Errorf("expected the request to use the given context")
+	}
+	if deadline, ok := outgoing.Context().Deadline(); !ok || time.Until(deadline) < time.Second*30 || time.Until(deadline) > time.Minute {
+		t.Errorf("expected a deadline a minute from now, got %v (%t)", deadline, ok)
+	}
+}
+
+func TestWithoutRedirects(t *testing.T) {
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/app/477160" {
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte("<html><body><h1>Login</h1></body></html>"))
+	}))
+
+	const AppPage URL = "%s://%s/app/%d"
+	_, resp, err := AppPage.SoupOpts([]any{host, 477160})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the redirect to be followed, got status %d", resp.StatusCode)
+	}
+	if expected := "https://" + host + "/login"; FinalURL(resp) != expected {
+		t.Errorf("expected the final URL to be %q, got %q", expected, FinalURL(resp))
+	}
+
+	if _, resp, err = AppPage.SoupOpts([]any{host, 477160}, WithoutRedirects()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("expected the redirect to be returned, got status %d", resp.StatusCode)
+	}
+	if location := resp.Header.Get("Location"); location != "/login" {
+		t.Errorf("expected the Location header to be /login, got %q", location)
+	}
+	if expected := AppPage.Fill(host, 477160); FinalURL(resp) != expected {
+		t.Errorf("expected the final URL to be %q, got %q", expected, FinalURL(resp))
+	}
+	if http.DefaultClient.CheckRedirect != nil {
+		t.Errorf("expected the default client to be left unmodified")
+	}
+
+	// A nil client falls back to the default HTTP client, even when it needs to be copied
+	if _, resp, err = AppPage.SoupOpts([]any{host, 477160}, WithClient(nil), WithoutRedirects()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("expected the redirect to be returned using a nil client, got status %d", resp.StatusCode)
+	}
+	if _, resp, err = AppPage.SoupOpts([]any{host, 477160}, WithClient(nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the redirect to be followed using a nil client, got status %d", resp.StatusCode)
+	}
+}
+
+func TestURL_Resolve(t *testing.T) {
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/s/abc":
+			http.Redirect(w, r, "/redirect?to=app", http.StatusMovedPermanently)
+		case "/redirect":
+			http.Redirect(w, r, "/app/477160", http.StatusFound)
+		default:
+			_, _ = w.Write([]byte("Human: Fall Flat"))
+		}
+	}))
+
+	const ShortLink URL = "%s://%s/s/%s"
+	finalURL, resp, err := ShortLink.Resolve(host, "abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "https://" + host + "/app/477160"; finalURL != expected {
+		t.Errorf("expected the final URL to be %q, got %q", expected, finalURL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	client := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 2 {
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}}
+	if finalURL, _, err = ShortLink.ResolveWithClient(client, nil, host, "abc"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "https://" + host + "/redirect?to=app"; finalURL != expected {
+		t.Errorf("expected the client's redirect policy to be honoured, got %q", finalURL)
+	}
+}
+
+func TestWithMaxBytes(t *testing.T) {
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Stream the body in chunks, so that it has no Content-Length
+		for i := 0; i < 64; i++ {
+			_, _ = w.Write([]byte(strings.Repeat("a", 1024)))
+			w.(http.Flusher).Flush()
+		}
+	}))
+
+	const Endpoint URL = "%s://%s/large"
+	if _, _, err := Endpoint.SoupOpts([]any{host}); err != nil {
+		t.Errorf("unexpected error for a body within DefaultMaxBytes: %v", err)
+	}
+
+	_, resp, err := Endpoint.SoupOpts([]any{host}, WithMaxBytes(16*1024))
+	if !errors.Is(err, ErrBodyTooLarge) {
+		t.Fatalf("expected ErrBodyTooLarge, got %v", err)
+	}
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.StatusCode != http.StatusOK || resp == nil {
+		t.Errorf("expected a FetchError with the status of the response, got %v", err)
+	}
+
+	if _, _, err = Endpoint.SoupOpts([]any{host}, WithMaxBytes(64*1024)); err != nil {
+		t.Errorf("unexpected error for a body exactly at the maximum: %v", err)
+	}
+
+	req, _ := http.NewRequestWithContext(ContextWithMaxBytes(context.Background(), 1024), http.MethodGet, Endpoint.Fill(host), nil)
+	if _, _, err = Endpoint.JSON(req); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("expected ErrBodyTooLarge using ContextWithMaxBytes, got %v", err)
+	}
+}
+
+func TestURL_JSONDecode_maxBytes(t *testing.T) {
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery == "malformed" {
+			_, _ = w.Write([]byte(`{"reviews":`))
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"reviews":"%s"}`, strings.Repeat("a", 64*1024))
+	}))
+
+	const Endpoint URL = "%s://%s/reviews"
+	req, _ := http.NewRequestWithContext(ContextWithMaxBytes(context.Background(), 16*1024), http.MethodGet, Endpoint.Fill(host), nil)
+	jsonBody, _, err := Endpoint.JSONDecode(req)
+	if !errors.Is(err, ErrBodyTooLarge) || jsonBody != nil {
+		t.Errorf("expected ErrBodyTooLarge and no JSON, got %v", err)
+	}
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.StatusCode != http.StatusOK {
+		t.Errorf("expected a FetchError with the status of the response, got %v", err)
+	}
+
+	req, _ = http.NewRequestWithContext(ContextWithMaxBytes(context.Background(), 128*1024), http.MethodGet, Endpoint.Fill(host), nil)
+	if jsonBody, _, err = Endpoint.JSONDecode(req); err != nil || len(jsonBody["reviews"].(string)) != 64*1024 {
+		t.Errorf("unexpected error for a body within the maximum: %v", err)
+	}
+
+	req, _ = http.NewRequestWithContext(ContextWithMaxBytes(context.Background(), 16*1024), http.MethodGet, Endpoint.Fill(host)+"?malformed", nil)
+	var parseErr *ParseError
+	if _, _, err = Endpoint.JSONDecode(req); errors.Is(err, ErrBodyTooLarge) || !errors.As(err, &parseErr) {
+		t.Errorf("expected a truncated body within the maximum to produce a ParseError, got %v", err)
+	}
+}
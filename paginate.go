@@ -0,0 +1,97 @@
+package urlfmt
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"net/http"
+)
+
+// PageFunc is called by URL.JSONPaginate with the parsed JSON of each page. It returns the args to Fill the URL with to
+// fetch the next page, or true if there are no more pages to fetch.
+type PageFunc func(page map[string]any) (nextArgs []any, done bool)
+
+// JSONPaginate repeatedly fetches pages of a paginated JSON resource using JSON, such as the Steam reviews endpoint
+// which returns a cursor that must be fed back into the next request. The first page is fetched using the given args,
+// and each subsequent page is fetched using the args returned by calling next with the previous page. JSONPaginate stops
+// once next returns true, or when an error occurs. A page that is fetched with a status other than 2xx is an error,
+// which wraps a FetchError containing the status code, and is not passed to next.
+//
+// If a non-nil http.Request is provided then it will be used to fetch the first page, and its headers and context will
+// be used for the requests for all subsequent pages. When the context is cancelled, JSONPaginate will return the
+// context's error before fetching the next page.
+//
+//	err := SteamAppReviews.JSONPaginate(nil, func(page map[string]any) ([]any, bool) {
+//		cursor, _ := page["cursor"].(string)
+//		return []any{477160, cursor}, cursor == ""
+//	}, 477160, "*")
+func (u URL) JSONPaginate(req *http.Request, next PageFunc, args ...any) error {
+	return u.paginate(req, nil, next, args...)
+}
+
+// JSONPaginateWith is the same as JSONPaginate, except that each page is fetched using RetryJSONWith with the given
+// RetryConfig. Pages that are fetched with a status other than 2xx are treated as failed tries, so that they are
+// retried according to the RetryConfig's ShouldRetry.
+func (u URL) JSONPaginateWith(req *http.Request, config RetryConfig, next PageFunc, args ...any) error {
+	return u.paginate(req, &config, next, args...)
+}
+
+// pageStatusError returns a FetchError if the given response for a page has a status other than 2xx, otherwise nil.
+func pageStatusError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return &FetchError{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Err:        fmt.Errorf("page was fetched with status %q", resp.Status),
+	}
+}
+
+// paginate implements JSONPaginate and JSONPaginateWith. If config is nil, then pages are not retried.
+func (u URL) paginate(req *http.Request, config *RetryConfig, next PageFunc, args ...any) (err error) {
+	ctx := context.Background()
+	var headers http.Header
+	if req != nil {
+		ctx = req.Context()
+		headers = req.Header.Clone()
+	}
+
+	for page := 0; ; page++ {
+		if err = ctx.Err(); err != nil {
+			return errors.Wrapf(err, "pagination of %s was cancelled before page %d", u.String(), page)
+		}
+
+		if req == nil {
+			if _, req, err = u.GetRequestWithHeaders(headers, args...); err != nil {
+				return errors.Wrapf(err, "could not create request for page %d of %s", page, u.String())
+			}
+			req = req.WithContext(ctx)
+		}
+
+		var jsonBody map[string]any
+		if config == nil {
+			var resp *http.Response
+			if jsonBody, resp, err = u.JSON(req); err == nil {
+				err = pageStatusError(resp)
+			}
+		} else {
+			err = u.RetryJSONWith(req, *config, func(body map[string]any, resp *http.Response) error {
+				if err := pageStatusError(resp); err != nil {
+					return err
+				}
+				jsonBody = body
+				return nil
+			})
+		}
+		if err != nil {
+			return errors.Wrapf(err, "could not fetch page %d of %s from %q", page, u.String(), req.URL.String())
+		}
+
+		nextArgs, done := next(jsonBody)
+		if done {
+			return nil
+		}
+		args, req = nextArgs, nil
+	}
+}
@@ -0,0 +1,121 @@
+package urlfmt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"net/http"
+	"strconv"
+	"testing"
+)
+
+// newPaginatedServer starts a TLS server that serves the given pages of reviews. Each page contains the cursor to use
+// to fetch the next page, which is empty for the last page. The request for a cursor within failures will fail with a
+// 503 the given number of times before succeeding.
+func newPaginatedServer(t *testing.T, pages [][]string, failures map[string]int) (host string, requests *int) {
+	requests = new(int)
+	_, host = newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		cursor := r.URL.Query().Get("cursor")
+		if failures[cursor] > 0 {
+			failures[cursor]--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+
+		page := 0
+		if cursor != "*" {
+			page, _ = strconv.Atoi(cursor)
+		}
+		nextCursor := ""
+		if page+1 < len(pages) {
+			nextCursor = strconv.Itoa(page + 1)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"reviews": pages[page], "cursor": nextCursor})
+	}))
+	return
+}
+
+func TestURL_JSONPaginate(t *testing.T) {
+	const SteamAppReviews URL = "%s://%s/appreviews/%d?json=1&cursor=%s"
+	pages := [][]string{{"a", "b"}, {"c"}, {"d", "e", "f"}}
+
+	collect := func(host string, reviews *[]string) PageFunc {
+		return func(page map[string]any) ([]any, bool) {
+			for _, review := range page["reviews"].([]any) {
+				*reviews = append(*reviews, review.(string))
+			}
+			cursor := page["cursor"].(string)
+			return []any{host, 477160, cursor}, cursor == ""
+		}
+	}
+
+	t.Run("JSONPaginate", func(t *testing.T) {
+		host, requests := newPaginatedServer(t, pages, nil)
+		var reviews []string
+		if err := SteamAppReviews.JSONPaginate(nil, collect(host, &reviews), host, 477160, "*"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if fmt.Sprint(reviews) != "[a b c d e f]" {
+			t.Errorf("expected all reviews to be collected, got %v", reviews)
+		}
+		if *requests != len(pages) {
+			t.Errorf("expected %d requests, got %d", len(pages), *requests)
+		}
+	})
+
+	t.Run("JSONPaginateWith", func(t *testing.T) {
+		host, requests := newPaginatedServer(t, pages, map[string]int{"1": 2})
+		var reviews []string
+		if err := SteamAppReviews.JSONPaginateWith(nil, RetryConfig{MaxTries: 3, Backoff: ConstantBackoff}, collect(host, &reviews), host, 477160, "*"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if fmt.Sprint(reviews) != "[a b c d e f]" {
+			t.Errorf("expected all reviews to be collected, got %v", reviews)
+		}
+		if *requests != len(pages)+2 {
+			t.Errorf("expected %d requests, got %d", len(pages)+2, *requests)
+		}
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		host, requests := newPaginatedServer(t, pages, map[string]int{"1": 1})
+		var reviews []string
+		err := SteamAppReviews.JSONPaginate(nil, collect(host, &reviews), host, 477160, "*")
+		var fetchErr *FetchError
+		if !errors.As(err, &fetchErr) || fetchErr.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("expected a FetchError with a %d status, got %v", http.StatusServiceUnavailable, err)
+		}
+		if fmt.Sprint(reviews) != "[a b]" {
+			t.Errorf("expected only the reviews of the first page to be collected, got %v", reviews)
+		}
+		if *requests != 2 {
+			t.Errorf("expected 2 requests, got %d", *requests)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		host, requests := newPaginatedServer(t, pages, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		_, req, err := SteamAppReviews.GetRequest(host, 477160, "*")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var reviews []string
+		next := collect(host, &reviews)
+		err = SteamAppReviews.JSONPaginate(req.WithContext(ctx), func(page map[string]any) ([]any, bool) {
+			cancel()
+			return next(page)
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected a cancellation error, got %v", err)
+		}
+		if *requests != 1 {
+			t.Errorf("expected 1 request before cancellation, got %d", *requests)
+		}
+	})
+}
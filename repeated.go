@@ -0,0 +1,128 @@
+package urlfmt
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// repeatedPattern matches a repeated query parameter marker within a URL format, e.g. "{tags...}". The first group is
+// the key of the query parameter. When filled, the marker consumes a slice arg and expands to one "key=value" pair per
+// element of the slice, joined by "&":
+//
+//	"%s://store.steampowered.com/search?{tags...}&page=%d"
+//
+// Filled with []string{"rpg", "co-op"} and 2, this produces:
+//
+//	"https://store.steampowered.com/search?tags=rpg&tags=co-op&page=2"
+var repeatedPattern = regexp.MustCompile(`\{([a-zA-Z0-9_\[\]-]+)\.\.\.}`)
+
+// tokenPattern matches either a string interpolation verb (see verbPattern) or a repeated query parameter marker (see
+// repeatedPattern). The first group is the letter of the verb, and the second group is the key of the query parameter.
+var tokenPattern = regexp.MustCompile(verbPattern.String() + "|" + repeatedPattern.String())
+
+// escapedAt returns whether the "%" at the given offset within the given format is escaped, i.e. it is preceded by an
+// odd number of "%", such as the second "%" within "%%d".
+func escapedAt(format string, offset int) bool {
+	preceding := 0
+	for i := offset - 1; i >= 0 && format[i] == '%'; i-- {
+		preceding++
+	}
+	return preceding%2 == 1
+}
+
+// findTokens returns the submatch indices of each token (see tokenPattern) within the given format. Verbs whose "%" is
+// escaped (see escapedAt) are skipped, so "%%d" is treated as the literal text "%d" rather than as a verb.
+func findTokens(format string) [][]int {
+	locs := tokenPattern.FindAllStringSubmatchIndex(format, -1)
+	tokens := locs[:0]
+	for _, loc := range locs {
+		if loc[2] >= 0 && escapedAt(format, loc[0]) {
+			continue
+		}
+		tokens = append(tokens, loc)
+	}
+	return tokens
+}
+
+// repeatedRegexSuffix is the suffix of every regex pattern produced by repeatedRegexPattern.
+const repeatedRegexSuffix = `=[^&#]*)*)?)`
+
+// repeatedRegexPattern returns the regex pattern that a repeated query parameter marker with the given key is converted
+// to by URL.Regex. The pattern matches zero or more "key=value" pairs joined by "&".
+func repeatedRegexPattern(key string) string {
+	key = regexp.QuoteMeta(key)
+	return fmt.Sprintf(`((?:%s=[^&#]*(?:&%s%s`, key, key, repeatedRegexSuffix)
+}
+
+// parseRepeated parses a string matched by a repeated query parameter pattern into a []string containing the
+// unescaped value of each "key=value" pair.
+func parseRepeated(s string) (any, error) {
+	values := make([]string, 0)
+	if s == "" {
+		return values, nil
+	}
+	for _, pair := range strings.Split(s, "&") {
+		_, value, _ := strings.Cut(pair, "=")
+		unescaped, err := url.QueryUnescape(value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, unescaped)
+	}
+	return values, nil
+}
+
+// fillRepeated expands a slice arg into "key=value" pairs for the repeated query parameter marker with the given key.
+// Each value is query escaped. If the arg is not a slice or an array, then it is treated as a single value.
+func fillRepeated(key string, arg any) string {
+	val := reflect.ValueOf(arg)
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		if arg == nil {
+			return ""
+		}
+		return key + "=" + url.QueryEscape(fmt.Sprint(arg))
+	}
+
+	pairs := make([]string, val.Len())
+	for i := 0; i < val.Len(); i++ {
+		pairs[i] = key + "=" + url.QueryEscape(fmt.Sprint(val.Index(i).Interface()))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// fillToken fills the given token (either a verb or a repeated query parameter marker) with the given arg.
+func fillToken(token string, arg any) string {
+	if groups := repeatedPattern.FindStringSubmatch(token); groups != nil {
+		return fillRepeated(groups[1], arg)
+	}
+	return fmt.Sprintf(fmtFormat(token), arg)
+}
+
+// expandRepeated replaces each repeated query parameter marker within the given format with a string verb, and
+// replaces the corresponding arg with its expanded "key=value" pairs, so that the returned format and args can be
+// passed straight to fmt.Sprintf. The given args should include the protocol.
+func expandRepeated(format string, args []any) (string, []any) {
+	if !repeatedPattern.MatchString(format) {
+		return format, args
+	}
+
+	expanded := append([]any(nil), args...)
+	var b strings.Builder
+	last := 0
+	for i, loc := range findTokens(format) {
+		if loc[4] < 0 {
+			continue
+		}
+		b.WriteString(format[last:loc[0]])
+		b.WriteString("%s")
+		if i < len(expanded) {
+			expanded[i] = fillRepeated(format[loc[4]:loc[5]], expanded[i])
+		}
+		last = loc[1]
+	}
+	b.WriteString(format[last:])
+	return b.String(), expanded
+}
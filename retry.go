@@ -0,0 +1,252 @@
+package urlfmt
+
+import (
+	"github.com/anaskhan96/soup"
+	"github.com/andygello555/agem"
+	"github.com/pkg/errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BackoffFunc computes how long to sleep after the given (zero-indexed) currentTry has failed, before the next try is
+// made. maxTries and minDelay are the values given in the RetryConfig.
+type BackoffFunc func(currentTry int, maxTries int, minDelay time.Duration) time.Duration
+
+// ConstantBackoff always sleeps for minDelay between tries.
+func ConstantBackoff(currentTry int, maxTries int, minDelay time.Duration) time.Duration {
+	return minDelay
+}
+
+// LinearBackoff sleeps for a delay that grows linearly with each failed try. This is the same formula used by
+// agem.Retry, and therefore by URL.RetrySoup and URL.RetryJSON:
+//
+//	minDelay * time.Duration(maxTries+1-tries)
+//
+// Where tries is the number of tries remaining. I.e. the first failed try will sleep for 2 * minDelay, the second for
+// 3 * minDelay, and so on.
+func LinearBackoff(currentTry int, maxTries int, minDelay time.Duration) time.Duration {
+	return minDelay * time.Duration(currentTry+2)
+}
+
+// ExponentialBackoff sleeps for minDelay * 2^currentTry between tries. The delay saturates at the maximum
+// time.Duration, rather than overflowing, for large values of currentTry (e.g. 34 and above for a minDelay of 1s).
+func ExponentialBackoff(currentTry int, maxTries int, minDelay time.Duration) time.Duration {
+	if currentTry >= 63 || minDelay > math.MaxInt64>>uint(currentTry) {
+		return math.MaxInt64
+	}
+	return minDelay * time.Duration(1<<uint(currentTry))
+}
+
+// ExponentialJitterBackoff is ExponentialBackoff with added jitter. The returned delay will be a random duration
+// within the upper half of the delay computed by ExponentialBackoff. This avoids multiple clients that failed at the
+// same time from retrying in lockstep.
+func ExponentialJitterBackoff(currentTry int, maxTries int, minDelay time.Duration) time.Duration {
+	delay := ExponentialBackoff(currentTry, maxTries, minDelay)
+	if delay <= 1 {
+		return delay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)))
+}
+
+// CappedBackoff wraps the given BackoffFunc so that it never returns a delay greater than max.
+func CappedBackoff(backoff BackoffFunc, max time.Duration) BackoffFunc {
+	return func(currentTry int, maxTries int, minDelay time.Duration) time.Duration {
+		if delay := backoff(currentTry, maxTries, minDelay); delay < max {
+			return delay
+		}
+		return max
+	}
+}
+
+// RetryConfig configures the retry loop used by URL.RetrySoupWith and URL.RetryJSONWith.
+type RetryConfig struct {
+	// MaxTries is the number of times a failed try will be retried.
+	MaxTries int
+	// MinDelay is passed to the Backoff function to compute the delay between tries.
+	MinDelay time.Duration
+	// Backoff computes the delay to sleep for after a failed try. If nil, LinearBackoff will be used.
+	Backoff BackoffFunc
+	// ShouldRetry decides whether a failed try should be retried, given the response from the try (which may be nil if
+	// the request itself failed) and the error that occurred. If nil, DefaultShouldRetry will be used. When a try is
+	// deemed not retryable the retry loop will return immediately.
+	ShouldRetry func(resp *http.Response, err error) bool
+	// OnAttempt is called after every try, including successful tries and the final failing try, with the zero-indexed
+	// attempt number, the response from the try (which may be nil), and the error that occurred (which is nil if the
+	// try succeeded). This is useful for logging or emitting metrics for each try. Callers own their callback: it is
+	// called synchronously within the retry loop, so it should not block, and any panic within it is recovered and
+	// ignored so that it cannot break the retry loop.
+	OnAttempt func(attempt int, resp *http.Response, err error)
+	// MaxDuration is the total time budget for the retry loop. If the time elapsed since the first try, plus the delay
+	// before the next try, would exceed MaxDuration then the retry loop returns the error from the last try rather than
+	// sleeping. This bounds the wall-clock time spent retrying, alongside MaxTries. If 0, there is no time budget.
+	MaxDuration time.Duration
+}
+
+// DefaultShouldRetry retries any try that failed without receiving a response, or that received a 429 Too Many
+// Requests or 5xx status. Tries that received any other 4xx status are not retried as the request is unlikely to
+// succeed on subsequent tries.
+func DefaultShouldRetry(resp *http.Response, err error) bool {
+	if resp == nil {
+		return true
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return true
+	case resp.StatusCode >= 400:
+		return false
+	default:
+		return true
+	}
+}
+
+// nonRetryableError wraps an error returned from a try that should not be retried. It can be converted to agem.Break
+// using errors.As so that agem.Retry stops retrying immediately.
+type nonRetryableError struct {
+	error
+}
+
+func (e nonRetryableError) Unwrap() error { return e.error }
+
+func (e nonRetryableError) As(target any) bool {
+	if rt, ok := target.(*agem.RetryReturnType); ok {
+		*rt = agem.Break
+		return true
+	}
+	return false
+}
+
+// backoff returns the delay to sleep for after the given try has failed.
+func (rc RetryConfig) backoff(currentTry int) time.Duration {
+	backoff := rc.Backoff
+	if backoff == nil {
+		backoff = LinearBackoff
+	}
+	return backoff(currentTry, rc.MaxTries, rc.MinDelay)
+}
+
+// shouldRetry returns whether a try that failed with the given response and error should be retried.
+func (rc RetryConfig) shouldRetry(resp *http.Response, err error) bool {
+	shouldRetry := rc.ShouldRetry
+	if shouldRetry == nil {
+		shouldRetry = DefaultShouldRetry
+	}
+	return shouldRetry(resp, err)
+}
+
+// onAttempt calls the RetryConfig's OnAttempt callback, if there is one, recovering from any panic within it.
+func (rc RetryConfig) onAttempt(attempt int, resp *http.Response, err error) {
+	if rc.OnAttempt == nil {
+		return
+	}
+	defer func() { _ = recover() }()
+	rc.OnAttempt(attempt, resp, err)
+}
+
+// retryAfter returns the duration that the given response asks the client to wait for before retrying, using the
+// Retry-After header. Both the delta-seconds and HTTP-date forms of the header are supported. If the response is nil,
+// the header is missing, or the header cannot be parsed then false is returned.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	header := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// delay returns the delay to sleep for after the given try has failed with the given response (which may be nil). This
+// is the delay computed by the RetryConfig's Backoff, unless the response contains a Retry-After header asking for a
+// longer delay.
+func (rc RetryConfig) delay(currentTry int, resp *http.Response) time.Duration {
+	delay := rc.backoff(currentTry)
+	if after, ok := retryAfter(resp); ok && after > delay {
+		delay = after
+	}
+	return delay
+}
+
+// retry calls the given function using agem.Retry, sleeping for the delay computed by the RetryConfig's Backoff after
+// each failed try that is not the last. If the response of the failed try contains a Retry-After header, then the retry
+// loop will sleep for at least the duration given by the header. If a failed try is not retryable according to the
+// RetryConfig's ShouldRetry then the error is returned immediately.
+func (rc RetryConfig) retry(try func(currentTry int) (*http.Response, error)) error {
+	start := time.Now()
+	return agem.Retry(rc.MaxTries, 0, func(currentTry int, maxTries int, minDelay time.Duration, args ...any) (err error) {
+		var resp *http.Response
+		resp, err = try(currentTry)
+		rc.onAttempt(currentTry, resp, err)
+		if err != nil {
+			if !rc.shouldRetry(resp, err) {
+				if resp != nil {
+					err = errors.Wrapf(err, "try %d received non-retryable status %q", currentTry+1, resp.Status)
+				} else {
+					err = errors.Wrapf(err, "try %d failed with a non-retryable error", currentTry+1)
+				}
+				return nonRetryableError{err}
+			}
+			if currentTry < maxTries {
+				delay := rc.delay(currentTry, resp)
+				if rc.MaxDuration > 0 && time.Since(start)+delay > rc.MaxDuration {
+					err = errors.Wrapf(err, "try %d failed, and retrying after %s would exceed the time budget of %s", currentTry+1, delay, rc.MaxDuration)
+					return nonRetryableError{err}
+				}
+				time.Sleep(delay)
+			}
+		}
+		return
+	})
+}
+
+// RetrySoupWith will run Soup with the given args and try the given function. If the function returns an error then
+// the function will be retried according to the given RetryConfig. If a non-nil http.Request is provided then it will
+// be used to fetch the page for the Soup, otherwise a default http.MethodGet http.Request will be constructed instead.
+func (u URL) RetrySoupWith(req *http.Request, config RetryConfig, try func(doc *soup.Root, resp *http.Response) error, args ...any) error {
+	return config.retry(func(currentTry int) (resp *http.Response, err error) {
+		var doc *soup.Root
+		if doc, resp, err = u.Soup(req, args...); err != nil {
+			return resp, errors.Wrapf(err, "ran out of tries (%d total) whilst requesting Soup for %s", config.MaxTries, u.String())
+		}
+		if err = try(doc, resp); err != nil {
+			return resp, errors.Wrapf(err, "ran out of tries (%d total) whilst calling try function for %s", config.MaxTries, u.String())
+		}
+		return resp, nil
+	})
+}
+
+// RetryJSONWith will run JSON with the given args and try the given function. If the function returns an error then
+// the function will be retried according to the given RetryConfig. If a non-nil http.Request is provided then it will
+// be used to fetch the JSON resource, otherwise default http.MethodGet http.Request will be constructed instead.
+func (u URL) RetryJSONWith(req *http.Request, config RetryConfig, try func(jsonBody map[string]any, resp *http.Response) error, args ...any) error {
+	return config.retry(func(currentTry int) (resp *http.Response, err error) {
+		var jsonBody map[string]any
+		if jsonBody, resp, err = u.JSON(req, args...); err != nil {
+			return resp, errors.Wrapf(err, "ran out of tries (%d total) whilst requesting JSON for %s", config.MaxTries, u.String())
+		}
+		if err = try(jsonBody, resp); err != nil {
+			return resp, errors.Wrapf(err, "ran out of tries (%d total) whilst calling try function for %s", config.MaxTries, u.String())
+		}
+		return resp, nil
+	})
+}
@@ -0,0 +1,307 @@
+package urlfmt
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackoffFunc(t *testing.T) {
+	const minDelay = time.Second
+	for _, test := range []struct {
+		name     string
+		backoff  BackoffFunc
+		expected []time.Duration
+	}{
+		{"constant", ConstantBackoff, []time.Duration{time.Second, time.Second, time.Second, time.Second}},
+		{"linear", LinearBackoff, []time.Duration{2 * time.Second, 3 * time.Second, 4 * time.Second, 5 * time.Second}},
+		{"exponential", ExponentialBackoff, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}},
+		{"capped", CappedBackoff(ExponentialBackoff, 3*time.Second), []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			for currentTry, expected := range test.expected {
+				if actual := test.backoff(currentTry, len(test.expected), minDelay); actual != expected {
+					t.Errorf("try %d: expected %s, got %s", currentTry, expected, actual)
+				}
+			}
+		})
+	}
+
+	t.Run("exponential jitter", func(t *testing.T) {
+		for currentTry := 0; currentTry < 8; currentTry++ {
+			upper := ExponentialBackoff(currentTry, 8, minDelay)
+			if actual := ExponentialJitterBackoff(currentTry, 8, minDelay); actual < upper/2 || actual > upper {
+				t.Errorf("try %d: expected a delay between %s and %s, got %s", currentTry, upper/2, upper, actual)
+			}
+		}
+	})
+
+	t.Run("exponential overflow", func(t *testing.T) {
+		capped := CappedBackoff(ExponentialBackoff, time.Minute)
+		for _, currentTry := range []int{33, 34, 40, 62, 63, 64, 100} {
+			if actual := ExponentialBackoff(currentTry, 128, minDelay); actual <= 0 {
+				t.Errorf("try %d: expected a positive delay, got %s", currentTry, actual)
+			}
+			if actual := capped(currentTry, 128, minDelay); actual != time.Minute {
+				t.Errorf("try %d: expected the capped delay to be %s, got %s", currentTry, time.Minute, actual)
+			}
+		}
+		if actual := ExponentialBackoff(64, 128, minDelay); actual != math.MaxInt64 {
+			t.Errorf("expected the delay to saturate at %s, got %s", time.Duration(math.MaxInt64), actual)
+		}
+	})
+}
+
+func TestURL_RetryJSONWith(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = fmt.Fprintf(w, `{"request":%d}`, requests)
+	}))
+	defer server.Close()
+
+	const Page URL = "%s://example.com/%d"
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var delays []time.Duration
+	config := RetryConfig{
+		MaxTries: 3,
+		MinDelay: time.Millisecond,
+		Backoff: func(currentTry int, maxTries int, minDelay time.Duration) time.Duration {
+			delay := ConstantBackoff(currentTry, maxTries, minDelay)
+			delays = append(delays, delay)
+			return delay
+		},
+	}
+
+	if err = Page.RetryJSONWith(req, config, func(jsonBody map[string]any, resp *http.Response) error {
+		if jsonBody["request"].(float64) < 3 {
+			return fmt.Errorf("request %v is not the third request", jsonBody["request"])
+		}
+		return nil
+	}, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+	if len(delays) != 2 {
+		t.Errorf("expected backoff to be computed twice, got %d", len(delays))
+	}
+
+	requests = 0
+	if err = Page.RetryJSONWith(req, config, func(jsonBody map[string]any, resp *http.Response) error {
+		return fmt.Errorf("request %v always fails", jsonBody["request"])
+	}, 1); err == nil {
+		t.Errorf("expected an error after running out of tries")
+	}
+	if requests != config.MaxTries+1 {
+		t.Errorf("expected %d requests, got %d", config.MaxTries+1, requests)
+	}
+}
+
+func TestRetryConfig_ShouldRetry(t *testing.T) {
+	for _, test := range []struct {
+		statuses         []int
+		expectedRequests int
+		err              bool
+	}{
+		{[]int{http.StatusNotFound}, 1, true},
+		{[]int{http.StatusUnauthorized}, 1, true},
+		{[]int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}, 3, false},
+		{[]int{http.StatusInternalServerError, http.StatusForbidden}, 2, true},
+	} {
+		t.Run(fmt.Sprintf("%v", test.statuses), func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := test.statuses[requests]
+				requests++
+				w.WriteHeader(status)
+				_, _ = fmt.Fprintf(w, `{"status":%d}`, status)
+			}))
+			defer server.Close()
+
+			const Page URL = "%s://example.com/%d"
+			req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = Page.RetryJSONWith(req, RetryConfig{MaxTries: 5, Backoff: ConstantBackoff}, func(jsonBody map[string]any, resp *http.Response) error {
+				if resp.StatusCode != http.StatusOK {
+					return fmt.Errorf("status %s", resp.Status)
+				}
+				return nil
+			}, 1)
+			if test.err && err == nil {
+				t.Errorf("expected an error")
+			} else if !test.err && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if requests != test.expectedRequests {
+				t.Errorf("expected %d requests, got %d", test.expectedRequests, requests)
+			}
+		})
+	}
+}
+
+func TestRetryConfig_RetryAfter(t *testing.T) {
+	t.Run("parse", func(t *testing.T) {
+		for _, test := range []struct {
+			header   string
+			expected time.Duration
+			ok       bool
+		}{
+			{"", 0, false},
+			{"2", 2 * time.Second, true},
+			{" 120 ", 2 * time.Minute, true},
+			{"-1", 0, false},
+			{"soon", 0, false},
+			{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+		} {
+			resp := &http.Response{Header: http.Header{}}
+			if test.header != "" {
+				resp.Header.Set("Retry-After", test.header)
+			}
+			if actual, ok := retryAfter(resp); actual != test.expected || ok != test.ok {
+				t.Errorf("%q: expected (%s, %t), got (%s, %t)", test.header, test.expected, test.ok, actual, ok)
+			}
+		}
+
+		resp := &http.Response{Header: http.Header{}}
+		resp.Header.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		if actual, ok := retryAfter(resp); !ok || actual < 59*time.Minute || actual > time.Hour {
+			t.Errorf("expected a delay of roughly an hour, got (%s, %t)", actual, ok)
+		}
+	})
+
+	t.Run("honored", func(t *testing.T) {
+		var times []time.Time
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			times = append(times, time.Now())
+			if len(times) == 1 {
+				w.Header().Set("Retry-After", "2")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+			_, _ = fmt.Fprintf(w, `{"request":%d}`, len(times))
+		}))
+		defer server.Close()
+
+		const Page URL = "%s://example.com/%d"
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = Page.RetryJSONWith(req, RetryConfig{MaxTries: 3, MinDelay: time.Millisecond}, func(jsonBody map[string]any, resp *http.Response) error {
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("status %s", resp.Status)
+			}
+			return nil
+		}, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(times) != 2 {
+			t.Fatalf("expected 2 requests, got %d", len(times))
+		}
+		if delay := times[1].Sub(times[0]); delay < 2*time.Second {
+			t.Errorf("expected a delay of at least 2s between requests, got %s", delay)
+		}
+	})
+}
+
+func TestRetryConfig_OnAttempt(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_, _ = fmt.Fprintf(w, `{"request":%d}`, requests)
+	}))
+	defer server.Close()
+
+	const Page URL = "%s://example.com/%d"
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	try := func(jsonBody map[string]any, resp *http.Response) error {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("status %s", resp.Status)
+		}
+		return nil
+	}
+
+	var attempts []string
+	config := RetryConfig{MaxTries: 5, Backoff: ConstantBackoff, OnAttempt: func(attempt int, resp *http.Response, err error) {
+		attempts = append(attempts, fmt.Sprintf("%d:%d:%t", attempt, resp.StatusCode, err != nil))
+		if attempt == 1 {
+			panic("callbacks should not be able to break the retry loop")
+		}
+	}}
+	if err = Page.RetryJSONWith(req, config, try, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "[0:503:true 1:503:true 2:200:false]"; fmt.Sprint(attempts) != expected {
+		t.Errorf("expected attempts %s, got %v", expected, attempts)
+	}
+
+	// The callback must also be called for the final failing attempt
+	attempts, requests = nil, -10
+	config.MaxTries = 2
+	if err = Page.RetryJSONWith(req, config, try, 1); err == nil {
+		t.Errorf("expected an error after running out of tries")
+	}
+	if len(attempts) != config.MaxTries+1 {
+		t.Errorf("expected %d attempts, got %v", config.MaxTries+1, attempts)
+	}
+}
+
+func TestRetryConfig_MaxDuration(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = fmt.Fprintf(w, `{"request":%d}`, requests)
+	}))
+	defer server.Close()
+
+	const Page URL = "%s://example.com/%d"
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := RetryConfig{
+		MaxTries:    10,
+		MinDelay:    time.Millisecond * 100,
+		Backoff:     ConstantBackoff,
+		MaxDuration: time.Millisecond * 250,
+	}
+	start := time.Now()
+	err = Page.RetryJSONWith(req, config, func(jsonBody map[string]any, resp *http.Response) error {
+		return fmt.Errorf("status %s", resp.Status)
+	}, 1)
+	elapsed := time.Since(start)
+
+	if err == nil || !strings.Contains(err.Error(), "time budget") {
+		t.Errorf("expected an error mentioning the time budget, got %v", err)
+	}
+	if elapsed > config.MaxDuration {
+		t.Errorf("expected the retry loop to return within %s, took %s", config.MaxDuration, elapsed)
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests within the time budget, got %d", requests)
+	}
+}
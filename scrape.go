@@ -0,0 +1,77 @@
+package urlfmt
+
+import (
+	"github.com/anaskhan96/soup"
+	"github.com/andygello555/agem"
+	"github.com/pkg/errors"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// selectorArgs parses a selector written in soup's shorthand into the args for soup.Root.Find. The following forms are
+// supported, where the tag can be omitted to match an element with any tag:
+//
+// • "tag": the first element with the given tag, e.g. "title".
+//
+// • "tag#id": the first element with the given tag and id, e.g. "div#appHubAppName".
+//
+// • "tag.class": the first element with the given tag that has the given class, e.g. "div.game_purchase_price".
+func selectorArgs(selector string) []string {
+	if i := strings.IndexAny(selector, "#."); i >= 0 {
+		attr := "id"
+		if selector[i] == '.' {
+			attr = "class"
+		}
+		return []string{selector[:i], attr, selector[i+1:]}
+	}
+	return []string{selector}
+}
+
+// Scrape fetches the URL using Soup, then finds the first element matching the selector for each field within the
+// given mapping of fields to selectors, returning a mapping of fields to the full text of the matched elements. Each
+// selector is written in soup's own shorthand: "tag", "tag#id", or "tag.class" (see selectorArgs). Fields whose
+// selector does not match an element are mapped to an empty string. Use ScrapeStrict to have an error returned for
+// these fields instead.
+func (u URL) Scrape(selectors map[string]string, args ...any) (fields map[string]string, resp *http.Response, err error) {
+	return u.scrape(selectors, false, args...)
+}
+
+// ScrapeStrict is the same as Scrape, except that an error wrapping ErrElementNotFound is returned for each field whose
+// selector does not match an element. The fields that were found are still returned alongside the error.
+func (u URL) ScrapeStrict(selectors map[string]string, args ...any) (fields map[string]string, resp *http.Response, err error) {
+	return u.scrape(selectors, true, args...)
+}
+
+func (u URL) scrape(selectors map[string]string, strict bool, args ...any) (fields map[string]string, resp *http.Response, err error) {
+	var doc *soup.Root
+	if doc, resp, err = u.Soup(nil, args...); err != nil {
+		return
+	}
+
+	// Sort the fields so that any errors are merged in a deterministic order
+	names := make([]string, 0, len(selectors))
+	for name := range selectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields = make(map[string]string, len(selectors))
+	errs := make([]error, 0)
+	for _, name := range names {
+		element := doc.Find(selectorArgs(selectors[name])...)
+		if element.Error != nil {
+			fields[name] = ""
+			if strict {
+				errs = append(errs, errors.Wrapf(ErrElementNotFound, "no element matches %q for field %q", selectors[name], name))
+			}
+			continue
+		}
+		fields[name] = strings.TrimSpace(element.FullText())
+	}
+
+	if len(errs) > 0 {
+		err = agem.MergeErrors(errs...)
+	}
+	return
+}
@@ -0,0 +1,80 @@
+package urlfmt
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func ExampleURL_Scrape() {
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	fields, _, err := SteamAppPage.Scrape(map[string]string{
+		"name":  "div#appHubAppName",
+		"price": "div.game_purchase_price",
+	}, 477160)
+	if err != nil {
+		fmt.Printf("Could not scrape %s: %v\n", SteamAppPage.Fill(477160), err)
+		return
+	}
+	fmt.Printf("%s costs %s\n", fields["name"], fields["price"])
+}
+
+func TestURL_Scrape(t *testing.T) {
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><body>
+			<div id="appHubAppName">Human: Fall Flat</div>
+			<div class="game_area_purchase"><div class="game_purchase_price price">
+				£15.99
+			</div></div>
+			<span>Free <b>DLC</b></span>
+		</body></html>`)
+	}))
+
+	const AppPage URL = "%s://%s/app/%d"
+	selectors := map[string]string{
+		"name":    "div#appHubAppName",
+		"price":   "div.game_purchase_price",
+		"dlc":     "span",
+		"reviews": "div#userReviews",
+	}
+	expected := map[string]string{
+		"name":    "Human: Fall Flat",
+		"price":   "£15.99",
+		"dlc":     "Free DLC",
+		"reviews": "",
+	}
+
+	fields, _, err := AppPage.Scrape(selectors, host, 477160)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %v, got %v", expected, fields)
+	}
+
+	fields, _, err = AppPage.ScrapeStrict(selectors, host, 477160)
+	if !errors.Is(err, ErrElementNotFound) {
+		t.Errorf("expected an error wrapping ErrElementNotFound, got %v", err)
+	}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %v, got %v", expected, fields)
+	}
+}
+
+func TestSelectorArgs(t *testing.T) {
+	for _, test := range []struct {
+		selector string
+		expected []string
+	}{
+		{"title", []string{"title"}},
+		{"div#appHubAppName", []string{"div", "id", "appHubAppName"}},
+		{"div.game_purchase_price", []string{"div", "class", "game_purchase_price"}},
+		{".price", []string{"", "class", "price"}},
+	} {
+		if actual := selectorArgs(test.selector); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("expected selectorArgs(%q) to return %v, got %v", test.selector, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package urlfmt
+
+import (
+	"github.com/anaskhan96/soup"
+	"net/http"
+	"net/http/cookiejar"
+)
+
+// Session wraps a http.Client that has a http.CookieJar, so that cookies set by the responses to previous requests are
+// sent with subsequent requests. This is useful for scraping pages that require a login. A Session can be used to fetch
+// any URL format.
+//
+//	session := NewSession()
+//	if _, _, err := session.JSON(LoginPage, loginReq); err != nil { ... }
+//	doc, resp, err := session.Soup(SteamAppPage, nil, 477160)
+type Session struct {
+	// Client is the http.Client used to make each request. It can be replaced or modified, but its Jar should be left
+	// intact for cookies to persist across requests.
+	Client *http.Client
+}
+
+// NewSession creates a new Session with a http.Client that has an empty in-memory cookiejar.Jar.
+func NewSession() *Session {
+	// cookiejar.New never returns an error
+	jar, _ := cookiejar.New(nil)
+	return &Session{Client: &http.Client{Jar: jar}}
+}
+
+// Soup fetches the given URL format filled with the given args using the Session's http.Client (see
+// URL.SoupWithClient).
+func (s *Session) Soup(u URL, req *http.Request, args ...any) (doc *soup.Root, resp *http.Response, err error) {
+	return u.SoupWithClient(s.Client, req, args...)
+}
+
+// JSON fetches the given URL format filled with the given args using the Session's http.Client (see
+// URL.JSONWithClient).
+func (s *Session) JSON(u URL, req *http.Request, args ...any) (jsonBody map[string]any, resp *http.Response, err error) {
+	return u.JSONWithClient(s.Client, req, args...)
+}
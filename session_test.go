@@ -0,0 +1,55 @@
+package urlfmt
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestSession(t *testing.T) {
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			http.SetCookie(w, &http.Cookie{Name: "sessionid", Value: "abc123", Path: "/"})
+			_, _ = fmt.Fprint(w, `{"success":true}`)
+			return
+		}
+
+		cookie, err := r.Cookie("sessionid")
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = fmt.Fprint(w, `<html><body><h1>Please log in</h1></body></html>`)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `<html><body><h1>Welcome back %s</h1></body></html>`, cookie.Value)
+	}))
+
+	const (
+		LoginPage   URL = "%s://%s/login"
+		AccountPage URL = "%s://%s/account"
+	)
+
+	session := NewSession()
+	if _, resp, err := session.JSON(LoginPage, nil, host); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from login, got %d", resp.StatusCode)
+	}
+
+	doc, resp, err := session.Soup(AccountPage, nil, host)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected the session cookie to be echoed back, got status %d", resp.StatusCode)
+	}
+	if actual := doc.Find("h1").Text(); actual != "Welcome back abc123" {
+		t.Errorf("expected %q, got %q", "Welcome back abc123", actual)
+	}
+
+	// The default client has no jar, so the cookie is not sent
+	if _, resp, err = AccountPage.Soup(nil, host); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a session, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,82 @@
+package urlfmt
+
+import (
+	"bufio"
+	"github.com/andygello555/agem"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Stream makes a request to the URL and calls onLine for each newline-delimited line of the response body as it
+// arrives, rather than buffering the entire response body like JSON and Soup. This is useful for endpoints that return
+// Server-Sent Events or newline-delimited JSON, which may never complete. The line passed to onLine does not include
+// the trailing "\n" or "\r\n", and empty lines are also passed (as they separate Server-Sent Events). The line is only
+// valid until onLine returns, so it should be copied if it needs to be kept.
+//
+// Streaming stops when the response body ends, in which case nil is returned, or when onLine returns an error, in which
+// case that error is returned, so that it can be checked for using errors.Is. If a non-nil http.Request is provided
+// then it will be used to make the request, otherwise a default http.MethodGet http.Request will be constructed
+// instead. Unlike JSON and Soup, DefaultTimeout is not applied, so the context of the request should be used to cancel
+// the stream. A line that is longer than the maximum number of bytes for the request (see ContextWithMaxBytes) results
+// in an error wrapping ErrBodyTooLarge. The response body is always closed before Stream returns.
+func (u URL) Stream(req *http.Request, onLine func(line []byte) error, args ...any) error {
+	return u.StreamWithClient(http.DefaultClient, req, onLine, args...)
+}
+
+// StreamWithClient is the same as Stream, except that the given http.Client is used to make the request (see
+// SoupWithClient). As the stream may never complete, the client should not have a Timeout. If the given client is nil
+// then the default HTTP client is used.
+func (u URL) StreamWithClient(client *http.Client, req *http.Request, onLine func(line []byte) error, args ...any) (err error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if req == nil {
+		if _, req, err = u.GetRequest(args...); err != nil {
+			return
+		}
+	}
+
+	var resp *http.Response
+	start := time.Now()
+	defer func() { observe(req.URL.String(), resp, start, err) }()
+
+	if resp, err = client.Do(req); err != nil {
+		return fetchError(err, req.URL.String(), 0, "could not get stream %s", req.URL.String())
+	}
+	defer func(body io.ReadCloser) {
+		err = agem.MergeErrors(err, errors.Wrapf(body.Close(), "could not close response body to %s", req.URL.String()))
+	}(resp.Body)
+
+	var reader io.ReadCloser
+	if reader, err = decompress(resp); err != nil {
+		return fetchError(err, req.URL.String(), resp.StatusCode, "could not decompress response body to %s", req.URL.String())
+	}
+	if reader != resp.Body {
+		defer func(reader io.ReadCloser) {
+			err = agem.MergeErrors(err, errors.Wrapf(reader.Close(), "could not close decompressor for response body to %s", req.URL.String()))
+		}(reader)
+	}
+
+	limit := maxBytes(req.Context())
+	scanner := bufio.NewScanner(reader)
+	size := int64(bufio.MaxScanTokenSize)
+	if limit < size {
+		size = limit
+	}
+	scanner.Buffer(make([]byte, 0, size), int(limit))
+	for scanner.Scan() {
+		if err = onLine(scanner.Bytes()); err != nil {
+			return
+		}
+	}
+
+	switch err = scanner.Err(); {
+	case errors.Is(err, bufio.ErrTooLong):
+		return fetchError(ErrBodyTooLarge, req.URL.String(), resp.StatusCode, "line of response body to %s exceeds %d bytes", req.URL.String(), limit)
+	case err != nil:
+		return fetchError(err, req.URL.String(), resp.StatusCode, "could not read response body to %s", req.URL.String())
+	}
+	return
+}
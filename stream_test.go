@@ -0,0 +1,156 @@
+package urlfmt
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestURL_Stream(t *testing.T) {
+	// release is closed once the first line has been received, so that the handler only finishes the stream after the
+	// client has seen a line, which proves that the lines are not buffered until the body ends
+	release := make(chan struct{})
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		flusher := w.(http.Flusher)
+		for i := 1; i <= 3; i++ {
+			_, _ = fmt.Fprintf(w, "{\"event\":%d}\r\n", i)
+			flusher.Flush()
+			if i == 1 {
+				select {
+				case <-release:
+				case <-r.Context().Done():
+					return
+				case <-time.After(time.Second * 5):
+					return
+				}
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+		_, _ = w.Write([]byte("\ndata: last"))
+	}))
+	Events := URL("%s://" + host + "/events/%s")
+
+	lines := make([]string, 0)
+	if err := Events.Stream(nil, func(line []byte) error {
+		if len(lines) == 0 {
+			close(release)
+		}
+		lines = append(lines, string(line))
+		return nil
+	}, "all"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{`{"event":1}`, `{"event":2}`, `{"event":3}`, "", "data: last"}; !reflect.DeepEqual(lines, expected) {
+		t.Errorf("expected lines %q, got %q", expected, lines)
+	}
+}
+
+func TestURL_Stream_stop(t *testing.T) {
+	closed := make(chan struct{}, 2)
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() { closed <- struct{}{} }()
+		for i := 0; ; i++ {
+			if _, err := fmt.Fprintf(w, "line %d\n", i); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(time.Millisecond * 5):
+			}
+		}
+	}))
+	Events := URL("%s://" + host + "/events")
+
+	stop := errors.New("stop")
+	count := 0
+	if err := Events.Stream(nil, func(line []byte) error {
+		if count++; count == 3 {
+			return stop
+		}
+		return nil
+	}); !errors.Is(err, stop) {
+		t.Errorf("expected the error returned by onLine, got %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected onLine to be called 3 times, got %d", count)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, req, err := Events.GetRequest()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	count = 0
+	err = Events.Stream(req.WithContext(ctx), func(line []byte) error {
+		if count++; count == 2 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-closed:
+		case <-time.After(time.Second * 5):
+			t.Fatal("expected each stream to be closed once Stream returned")
+		}
+	}
+}
+
+func TestURL_Stream_maxBytes(t *testing.T) {
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("short\nthis line is far too long\n"))
+	}))
+	Events := URL("%s://" + host + "/events")
+
+	_, req, err := Events.GetRequest()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := make([]string, 0)
+	err = Events.Stream(req.WithContext(ContextWithMaxBytes(req.Context(), 10)), func(line []byte) error {
+		lines = append(lines, string(line))
+		return nil
+	})
+	if !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("expected ErrBodyTooLarge, got %v", err)
+	}
+	if !reflect.DeepEqual(lines, []string{"short"}) {
+		t.Errorf("expected only the short line, got %q", lines)
+	}
+}
+
+func TestURL_StreamWithClient(t *testing.T) {
+	server, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "token %s\n", r.Header.Get("Authorization"))
+	}))
+	Events := URL("%s://" + host + "/events")
+
+	transport := server.Client().Transport
+	client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "secret")
+		return transport.RoundTrip(req)
+	})}
+
+	lines := make([]string, 0)
+	if err := Events.StreamWithClient(client, nil, func(line []byte) error {
+		lines = append(lines, string(line))
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(lines, []string{"token secret"}) {
+		t.Errorf("expected the stream to be fetched using the given client, got %q", lines)
+	}
+}
@@ -0,0 +1,122 @@
+package urlfmt
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// structTag is the key of the struct tag that is read by URL.FillStruct.
+const structTag = "urlfmt"
+
+// FillStruct is the same as Fill, except that the args are taken from the exported fields of the given struct, or
+// pointer to a struct, rather than being given positionally. This is less error-prone than a long list of positional
+// args, e.g.
+//
+//	type reviewsQuery struct {
+//		AppID  int
+//		Cursor string
+//		Filter string
+//	}
+//	SteamAppReviews.FillStruct(reviewsQuery{AppID: 477160, Cursor: "*", Filter: "recent"})
+//
+// By default, the fields are given to the verbs in the order that they are declared. The order can be given explicitly
+// using a "urlfmt" struct tag holding the position of the verb, starting at 0, that the field fills, e.g.
+// `urlfmt:"2"`. If any field has a position then every field must have one. Fields tagged with `urlfmt:"-"` are
+// skipped. Nil pointer fields are given as nil, so that optional sections (see Fill) can be omitted, otherwise pointers
+// are dereferenced.
+//
+// An error is returned if v is not a struct, if the number of fields does not match the number of verbs (see
+// NumVerbs), if the positions are invalid, or if the type of a field is not compatible with the type of its verb (see
+// VerbInfo.Kind), e.g. a string field for a "%d" verb.
+func (u URL) FillStruct(v any) (string, error) {
+	val := reflect.ValueOf(v)
+	for val.Kind() == reflect.Pointer && !val.IsNil() {
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return "", fmt.Errorf("cannot fill %s from %T, as it is not a struct", u, v)
+	}
+
+	type field struct {
+		name     string
+		value    reflect.Value
+		position int
+	}
+	fields := make([]field, 0, val.NumField())
+	positioned := 0
+	for i := 0; i < val.NumField(); i++ {
+		structField := val.Type().Field(i)
+		tag, tagged := structField.Tag.Lookup(structTag)
+		if !structField.IsExported() || tag == "-" {
+			continue
+		}
+
+		f := field{name: structField.Name, value: val.Field(i), position: len(fields)}
+		if tagged {
+			position, err := strconv.Atoi(tag)
+			if err != nil {
+				return "", errors.Wrapf(err, "position %q of field %s is not an integer", tag, f.name)
+			}
+			f.position = position
+			positioned++
+		}
+		fields = append(fields, f)
+	}
+
+	verbs := u.Verbs()
+	if len(fields) != len(verbs) {
+		return "", fmt.Errorf("%T has %d fields but %s has %d verbs", v, len(fields), u, len(verbs))
+	}
+	if positioned > 0 {
+		if positioned != len(fields) {
+			return "", fmt.Errorf("only %d of the %d fields of %T have a position", positioned, len(fields), v)
+		}
+		sort.SliceStable(fields, func(i, j int) bool { return fields[i].position < fields[j].position })
+	}
+
+	args := make([]any, len(fields))
+	for i, f := range fields {
+		if f.position != i {
+			return "", fmt.Errorf("position %d of field %s is either out of range or used more than once", f.position, f.name)
+		}
+
+		value := f.value
+		for value.Kind() == reflect.Pointer && !value.IsNil() {
+			value = value.Elem()
+		}
+		if value.Kind() == reflect.Pointer {
+			continue
+		}
+		if !fillableBy(value, verbs[i]) {
+			return "", fmt.Errorf("field %s of type %s cannot fill the %%%s verb", f.name, value.Type(), verbs[i].Verb)
+		}
+		args[i] = value.Interface()
+	}
+	return u.Fill(args...), nil
+}
+
+// fillableBy returns whether the given value can be used to fill the given verb, by comparing the kind of the value
+// with the kind of the verb (see VerbInfo.Kind).
+func fillableBy(value reflect.Value, verb VerbInfo) bool {
+	switch verb.Kind {
+	case reflect.String:
+		_, stringer := value.Interface().(fmt.Stringer)
+		return stringer || value.Kind() == reflect.String ||
+			(value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8)
+	case reflect.Int64, reflect.Int32:
+		return value.CanInt() || value.CanUint()
+	case reflect.Float64:
+		return value.CanFloat()
+	case reflect.Bool:
+		return value.Kind() == reflect.Bool
+	case reflect.Uintptr:
+		return value.Kind() == reflect.Uintptr || value.Kind() == reflect.UnsafePointer
+	case reflect.Slice:
+		return value.Kind() == reflect.Slice || value.Kind() == reflect.Array
+	default:
+		return true
+	}
+}
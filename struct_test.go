@@ -0,0 +1,101 @@
+package urlfmt
+
+import (
+	"fmt"
+	"testing"
+)
+
+func ExampleURL_FillStruct() {
+	const SteamAppReviews URL = "%s://store.steampowered.com/appreviews/%d?json=1&cursor=%s&language=%s&num_per_page=%d&filter=%s"
+	type reviewsQuery struct {
+		AppID      int
+		Cursor     string
+		Language   string
+		NumPerPage int
+		Filter     string
+	}
+
+	fmt.Println(SteamAppReviews.FillStruct(reviewsQuery{
+		AppID:      477160,
+		Cursor:     "*",
+		Language:   "english",
+		NumPerPage: 100,
+		Filter:     "recent",
+	}))
+	// Output:
+	// https://store.steampowered.com/appreviews/477160?json=1&cursor=*&language=english&num_per_page=100&filter=recent <nil>
+}
+
+func TestURL_FillStruct(t *testing.T) {
+	const SteamAppReviews URL = "%s://store.steampowered.com/appreviews/%d?json=1&cursor=%s&language=%s&day_range=9223372036854775807&num_per_page=%d&review_type=all&purchase_type=%s&filter=%s&start_date=%d&end_date=%d&date_range_type=%s"
+	type reviewsQuery struct {
+		StartDate     int64  `urlfmt:"6"`
+		EndDate       int64  `urlfmt:"7"`
+		DateRangeType string `urlfmt:"8"`
+		AppID         uint32 `urlfmt:"0"`
+		Cursor        string `urlfmt:"1"`
+		Language      string `urlfmt:"2"`
+		NumPerPage    *int   `urlfmt:"3"`
+		PurchaseType  string `urlfmt:"4"`
+		Filter        string `urlfmt:"5"`
+		ignored       bool
+		Ignored       bool `urlfmt:"-"`
+	}
+
+	numPerPage := 20
+	query := reviewsQuery{
+		StartDate:     1699142400,
+		EndDate:       1699228800,
+		DateRangeType: "include",
+		AppID:         477160,
+		Cursor:        "*",
+		Language:      "english",
+		NumPerPage:    &numPerPage,
+		PurchaseType:  "all",
+		Filter:        "recent",
+	}
+	expected := SteamAppReviews.Fill(477160, "*", "english", 20, "all", "recent", 1699142400, 1699228800, "include")
+	for _, v := range []any{query, &query} {
+		filled, err := SteamAppReviews.FillStruct(v)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if filled != expected {
+			t.Errorf("expected %q, got %q", expected, filled)
+		}
+	}
+
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d%{?l=%s%}"
+	type appPage struct {
+		AppID    int
+		Language *string
+	}
+	if filled, err := SteamAppPage.FillStruct(appPage{AppID: 620}); err != nil || filled != "https://store.steampowered.com/app/620" {
+		t.Errorf("expected a nil pointer field to omit the optional section, got %q (%v)", filled, err)
+	}
+
+	for _, test := range []struct {
+		name string
+		v    any
+	}{
+		{"not a struct", 477160},
+		{"too few fields", struct{ AppID int }{477160}},
+		{"type mismatch", struct{ AppID, Language string }{"477160", "english"}},
+		{"partial positions", struct {
+			AppID    int `urlfmt:"0"`
+			Language string
+		}{477160, "english"}},
+		{"duplicate positions", struct {
+			AppID    int    `urlfmt:"0"`
+			Language string `urlfmt:"0"`
+		}{477160, "english"}},
+		{"non-integer position", struct {
+			AppID    int    `urlfmt:"first"`
+			Language string `urlfmt:"1"`
+		}{477160, "english"}},
+	} {
+		if filled, err := SteamAppPage.FillStruct(test.v); err == nil {
+			t.Errorf("%s: expected an error, got %q", test.name, filled)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package urlfmt
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// Unmarshal extracts the arguments from the given URL (see URL.ExtractArgs) and assigns each of them, in order, to the
+// given destination pointers. Numeric arguments are converted to the kind of their destination, so an argument parsed
+// as an int64 can be assigned to an *int, for instance. An error is returned if the URL does not match the format, if
+// the number of destinations does not match the number of extracted arguments, or if an argument cannot be assigned to
+// its destination.
+//
+//	var appID int
+//	err := SteamAppPage.Unmarshal("https://store.steampowered.com/app/477160", &appID)
+func (u URL) Unmarshal(url string, dest ...any) (err error) {
+	var args []any
+	if args, err = u.ExtractArgsErr(url); err != nil {
+		return errors.Wrapf(err, "could not extract args from %q", url)
+	}
+
+	if len(args) != len(dest) {
+		return fmt.Errorf("%d args were extracted from %q but %d destinations were given", len(args), url, len(dest))
+	}
+
+	for i, arg := range args {
+		if err = assignArg(dest[i], arg); err != nil {
+			return errors.Wrapf(err, "could not assign arg %d extracted from %q", i, url)
+		}
+	}
+	return
+}
+
+// assignArg assigns the given extracted argument to the value pointed to by dest, converting between numeric kinds when
+// necessary.
+func assignArg(dest any, arg any) error {
+	ptr := reflect.ValueOf(dest)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() {
+		return fmt.Errorf("destination must be a non-nil pointer, not %T", dest)
+	}
+
+	elem := ptr.Elem()
+	if arg == nil {
+		elem.Set(reflect.Zero(elem.Type()))
+		return nil
+	}
+
+	val := reflect.ValueOf(arg)
+	if val.Type().AssignableTo(elem.Type()) {
+		elem.Set(val)
+		return nil
+	}
+
+	switch elem.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch {
+		case val.CanInt():
+			i = val.Int()
+		case val.CanUint():
+			i = int64(val.Uint())
+		default:
+			return fmt.Errorf("cannot assign %v (%T) to %s", arg, arg, elem.Type())
+		}
+		if elem.OverflowInt(i) {
+			return fmt.Errorf("%v overflows %s", arg, elem.Type())
+		}
+		elem.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var i uint64
+		switch {
+		case val.CanInt() && val.Int() >= 0:
+			i = uint64(val.Int())
+		case val.CanUint():
+			i = val.Uint()
+		default:
+			return fmt.Errorf("cannot assign %v (%T) to %s", arg, arg, elem.Type())
+		}
+		if elem.OverflowUint(i) {
+			return fmt.Errorf("%v overflows %s", arg, elem.Type())
+		}
+		elem.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch {
+		case val.CanFloat():
+			f = val.Float()
+		case val.CanInt():
+			f = float64(val.Int())
+		case val.CanUint():
+			f = float64(val.Uint())
+		default:
+			return fmt.Errorf("cannot assign %v (%T) to %s", arg, arg, elem.Type())
+		}
+		if elem.OverflowFloat(f) {
+			return fmt.Errorf("%v overflows %s", arg, elem.Type())
+		}
+		elem.SetFloat(f)
+	default:
+		if !val.Type().ConvertibleTo(elem.Type()) || val.Kind() != elem.Kind() {
+			return fmt.Errorf("cannot assign %v (%T) to %s", arg, arg, elem.Type())
+		}
+		elem.Set(val.Convert(elem.Type()))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package urlfmt
+
+import (
+	"fmt"
+	"testing"
+)
+
+func ExampleURL_Unmarshal() {
+	const (
+		SteamAppPage   URL = "%s://store.steampowered.com/app/%d"
+		ItchIOGamePage URL = "%s://%s.itch.io/%s"
+	)
+
+	var appID int
+	if err := SteamAppPage.Unmarshal("https://store.steampowered.com/app/477160", &appID); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(appID)
+
+	var developer, game string
+	if err := ItchIOGamePage.Unmarshal("https://sokpop.itch.io/ballspell", &developer, &game); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(developer, game)
+	// Output:
+	// 477160
+	// sokpop ballspell
+}
+
+func TestURL_Unmarshal(t *testing.T) {
+	const (
+		SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+		FloatPage    URL = "%s://example.com/price/%f"
+	)
+
+	var (
+		appID    int
+		appID8   int8
+		appIDU   uint32
+		appIDStr string
+		price    float32
+		priceInt int
+		other    int
+	)
+
+	for i, test := range []struct {
+		u        URL
+		url      string
+		dest     []any
+		expected []any
+		err      bool
+	}{
+		{SteamAppPage, "https://store.steampowered.com/app/477160", []any{&appID}, []any{477160}, false},
+		{SteamAppPage, "https://store.steampowered.com/app/477160", []any{&appIDU}, []any{uint32(477160)}, false},
+		{FloatPage, "https://example.com/price/1.50", []any{&price}, []any{float32(1.5)}, false},
+		{SteamAppPage, "https://store.steampowered.com/app/477160", []any{}, nil, true},
+		{SteamAppPage, "https://store.steampowered.com/app/477160", []any{&appID, &other}, nil, true},
+		{SteamAppPage, "https://store.steampowered.com/app/477160", []any{&appID8}, nil, true},
+		{SteamAppPage, "https://store.steampowered.com/app/477160", []any{&appIDStr}, nil, true},
+		{SteamAppPage, "https://store.steampowered.com/app/477160", []any{appID}, nil, true},
+		{FloatPage, "https://example.com/price/1.50", []any{&priceInt}, nil, true},
+		{SteamAppPage, "https://store.steampowered.com/app/Human_Fall_Flat", []any{&appID}, nil, true},
+	} {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			err := test.u.Unmarshal(test.url, test.dest...)
+			if test.err {
+				if err == nil {
+					t.Errorf("expected an error when unmarshalling %q into %d destinations", test.url, len(test.dest))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for j, dest := range test.dest {
+				switch d := dest.(type) {
+				case *int:
+					if *d != test.expected[j] {
+						t.Errorf("dest %d is %v, expected %v", j, *d, test.expected[j])
+					}
+				case *uint32:
+					if *d != test.expected[j] {
+						t.Errorf("dest %d is %v, expected %v", j, *d, test.expected[j])
+					}
+				case *float32:
+					if *d != test.expected[j] {
+						t.Errorf("dest %d is %v, expected %v", j, *d, test.expected[j])
+					}
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,12 @@
 package urlfmt
 
 import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/anaskhan96/soup"
@@ -8,10 +14,16 @@ import (
 	"github.com/pkg/errors"
 	"io"
 	"net/http"
+	"net/url"
+	"path"
+	"reflect"
 	"regexp"
+	"regexp/syntax"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type verb string
@@ -41,53 +53,120 @@ const (
 	floatVerb verb = "f"
 	// floatSynonymVerb: synonym for %f
 	floatSynonymVerb verb = "F"
-	// floatHexLowerVerb: hexadecimal notation (with decimal power of two exponent), e.g. -0x1.23abcp+20
-	floatHexLowerVerb verb = "x"
-	// floatHexUpperVerb: upper-case hexadecimal notation, e.g. -0X1.23ABCP+20
-	floatHexUpperVerb verb = "X"
+	// generalLowerVerb: %e for large exponents, %f otherwise
+	generalLowerVerb verb = "g"
+	// generalUpperVerb: %E for large exponents, %F otherwise
+	generalUpperVerb verb = "G"
+	// hexLowerVerb: base 16, with lower-case letters for a-f, or for floats, hexadecimal notation (with decimal power of
+	// two exponent), e.g. -0x1.23abcp+20
+	hexLowerVerb verb = "x"
+	// hexUpperVerb: base 16, with upper-case letters for A-F, or for floats, upper-case hexadecimal notation, e.g.
+	// -0X1.23ABCP+20
+	hexUpperVerb verb = "X"
+	// base2PrefixVerb: base 2 with 0b prefix
+	base2PrefixVerb verb = "#b"
+	// base8ZeroPrefixVerb: base 8 with leading 0
+	base8ZeroPrefixVerb verb = "#o"
+	// hexLowerPrefixVerb: base 16, with lower-case letters for a-f and 0x prefix
+	hexLowerPrefixVerb verb = "#x"
+	// hexUpperPrefixVerb: base 16, with upper-case letters for A-F and 0X prefix
+	hexUpperPrefixVerb verb = "#X"
+	// pathStringVerb: the uninterpreted bytes of the string or slice, the same as %s. When matching, this also matches
+	// the sub-delimiters, ":", and "@" that can appear within a path segment, e.g. "%s://itch.io/profile/%#s" matches
+	// "https://itch.io/profile/@hempuli". The "#" flag has no effect on the output of %s, so this fills the same as %s.
+	pathStringVerb verb = "#s"
+	// pointerVerb: base 16 notation, with leading 0x
+	pointerVerb verb = "p"
+	// wildcardVerb: one or more path segments, including the slashes between them. This is not understood by the fmt
+	// package, so it is filled using "%s".
+	wildcardVerb verb = "*"
 )
 
 type verbRegexPattern string
 
 const (
 	// stringVerbRegexPattern: the uninterpreted bytes of the string or slice
-	stringVerbRegexPattern verbRegexPattern = `([a-zA-Z0-9-._~]+)`
+	stringVerbRegexPattern verbRegexPattern = `((?:[a-zA-Z0-9-._~]|%[0-9A-Fa-f]{2})+)`
+	// queryStringVerbRegexPattern: the uninterpreted bytes of the string or slice, when within the query of a URL.
+	// This also matches "+", which is decoded to a space.
+	queryStringVerbRegexPattern verbRegexPattern = `((?:[a-zA-Z0-9-._~+]|%[0-9A-Fa-f]{2})+)`
+	// rawQueryStringVerbRegexPattern: the uninterpreted bytes of the string or slice, when it is the entire query of a
+	// URL, e.g. "%s://itch.io/search?%s". This matches everything up to the fragment, including "&" and "=", so that
+	// the raw query is captured as is, e.g. "q=baba&page=2".
+	rawQueryStringVerbRegexPattern verbRegexPattern = `([^#\s]*)`
+	// opaqueStringVerbRegexPattern: the uninterpreted bytes of the string or slice, when within the path of an opaque
+	// URI, e.g. "mailto:%s" or "tel:%s". This also matches the sub-delimiters, ":", and "@", so that email addresses
+	// and phone numbers can be matched, but not "&" so that query parameters are still separated.
+	opaqueStringVerbRegexPattern verbRegexPattern = `((?:[a-zA-Z0-9-._~!$'()*+,;=:@]|%[0-9A-Fa-f]{2})+)`
+	// pathStringVerbRegexPattern: the uninterpreted bytes of the string or slice, including any character that RFC 3986
+	// allows within a path segment without being percent-encoded, e.g. "@username" or "a+b,c". This does not match
+	// "/", "?", or "#", so that it never matches across path segments.
+	pathStringVerbRegexPattern verbRegexPattern = `((?:[a-zA-Z0-9-._~!$&'()*+,;=:@]|%[0-9A-Fa-f]{2})+)`
+	// ipv6StringVerbRegexPattern: the uninterpreted bytes of the string or slice, when between the brackets of an IPv6
+	// literal host, e.g. "[%s]". This matches hex groups separated by colons, and IPv4-mapped addresses, e.g. ::1,
+	// 2001:db8::1, or ::ffff:192.0.2.1.
+	ipv6StringVerbRegexPattern verbRegexPattern = `([0-9A-Fa-f:.]*:[0-9A-Fa-f:.]*)`
 	// boolVerbRegexPattern: the word true or false
 	boolVerbRegexPattern verbRegexPattern = `(true|false)`
-	// base2VerbRegexPattern: base 2
-	base2VerbRegexPattern verbRegexPattern = `([01]+)`
-	// charVerbRegexPattern: the character represented by the corresponding Unicode code point
-	charVerbRegexPattern verbRegexPattern = `(.)`
-	// base8VerbRegexPattern: base 8
-	base8VerbRegexPattern verbRegexPattern = `([0-7]+)`
+	// base2VerbRegexPattern: base 2, with an optional sign, e.g. -101
+	base2VerbRegexPattern verbRegexPattern = `([+-]?[01]+)`
+	// charVerbRegexPattern: the character represented by the corresponding Unicode code point. URL delimiters are
+	// excluded so that the character can never swallow the structure of the URL.
+	charVerbRegexPattern verbRegexPattern = `([^/?#&])`
+	// base8VerbRegexPattern: base 8, with an optional sign, e.g. -17
+	base8VerbRegexPattern verbRegexPattern = `([+-]?[0-7]+)`
 	// base8PrefixVerbRegexPattern: base 8 with 0o prefix
-	base8PrefixVerbRegexPattern verbRegexPattern = `(0o[0-7]+)`
-	// base10VerbRegexPattern: base 10
-	base10VerbRegexPattern verbRegexPattern = `(\d+)`
+	base8PrefixVerbRegexPattern verbRegexPattern = `([+-]?0o[0-7]+)`
+	// base10VerbRegexPattern: base 10, with an optional sign, e.g. -1 or +5
+	base10VerbRegexPattern verbRegexPattern = `([+-]?\d+)`
 	// unicodeVerbRegexPattern: Unicode format: U+1234; same as "U+%04X"
-	unicodeVerbRegexPattern verbRegexPattern = `(U\+[0-9]+)`
-	// scientificNotationLowerVerbRegexPattern: scientific notation, e.g. -1.234456e+78
-	scientificNotationLowerVerbRegexPattern verbRegexPattern = `([+-]?[0-9]+\.[0-9]+e\+[0-9]+)`
-	// scientificNotationUpperVerbRegexPattern: scientific notation, e.g. -1.234456E+78
-	scientificNotationUpperVerbRegexPattern verbRegexPattern = `([+-]?[0-9]+\.[0-9]+E\+[0-9]+)`
+	unicodeVerbRegexPattern verbRegexPattern = `(U\+[0-9A-Fa-f]+)`
+	// scientificNotationLowerVerbRegexPattern: scientific notation, e.g. -1.234456e+78 or 1.5e-10
+	scientificNotationLowerVerbRegexPattern verbRegexPattern = `([+-]?[0-9]+\.[0-9]+e[+-][0-9]+)`
+	// scientificNotationUpperVerbRegexPattern: scientific notation, e.g. -1.234456E+78 or 1.5E-10
+	scientificNotationUpperVerbRegexPattern verbRegexPattern = `([+-]?[0-9]+\.[0-9]+E[+-][0-9]+)`
 	// floatVerbRegexPattern: decimal point but no exponent, e.g. 123.456
 	floatVerbRegexPattern verbRegexPattern = `([+-]?[0-9]+\.[0-9]+)`
 	// floatSynonymVerbRegexPattern: synonym for %f
 	floatSynonymVerbRegexPattern verbRegexPattern = `([+-]?[0-9]+\.[0-9]+)`
-	// floatHexLowerVerbRegexPattern: hexadecimal notation (with decimal power of two exponent), e.g. -0x1.23abcp+20
-	floatHexLowerVerbRegexPattern verbRegexPattern = `([+-]?0x[a-f0-9]+\.[0-9]+p\+[a-f0-9]+)`
-	// floatHexUpperVerbRegexPattern: upper-case hexadecimal notation, e.g. -0X1.23ABCP+20
-	floatHexUpperVerbRegexPattern verbRegexPattern = `([+-]?0x[A-F0-9]+\.[0-9]+P\+[A-F0-9]+)`
+	// generalLowerVerbRegexPattern: the shortest representation of a float, which is either decimal with an optional
+	// decimal point, or scientific notation, e.g. 100000, 1.5, or 1e+20
+	generalLowerVerbRegexPattern verbRegexPattern = `([+-]?[0-9]+(?:\.[0-9]+)?(?:e[+-][0-9]+)?)`
+	// generalUpperVerbRegexPattern: the same as generalLowerVerbRegexPattern but with an upper-case exponent, e.g.
+	// 1E+20
+	generalUpperVerbRegexPattern verbRegexPattern = `([+-]?[0-9]+(?:\.[0-9]+)?(?:E[+-][0-9]+)?)`
+	// hexLowerVerbRegexPattern: base 16, with lower-case letters for a-f, e.g. ff00aa, or hexadecimal notation (with
+	// decimal power of two exponent), e.g. -0x1.23abcp+20
+	hexLowerVerbRegexPattern verbRegexPattern = `([+-]?0x[0-9a-f]\.?[0-9a-f]*p[+-][0-9]+|[+-]?[0-9a-f]+)`
+	// hexUpperVerbRegexPattern: base 16, with upper-case letters for A-F, e.g. FF00AA, or upper-case hexadecimal
+	// notation, e.g. -0X1.23ABCP+20
+	hexUpperVerbRegexPattern verbRegexPattern = `([+-]?0X[0-9A-F]\.?[0-9A-F]*P[+-][0-9]+|[+-]?[0-9A-F]+)`
+	// base2PrefixVerbRegexPattern: base 2 with 0b prefix
+	base2PrefixVerbRegexPattern verbRegexPattern = `([+-]?0b[01]+)`
+	// base8ZeroPrefixVerbRegexPattern: base 8 with leading 0
+	base8ZeroPrefixVerbRegexPattern verbRegexPattern = `([+-]?0[0-7]*)`
+	// hexLowerPrefixVerbRegexPattern: base 16, with lower-case letters for a-f and 0x prefix, e.g. 0xff00aa
+	hexLowerPrefixVerbRegexPattern verbRegexPattern = `([+-]?0x[0-9a-f]+)`
+	// hexUpperPrefixVerbRegexPattern: base 16, with upper-case letters for A-F and 0X prefix, e.g. 0XFF00AA
+	hexUpperPrefixVerbRegexPattern verbRegexPattern = `([+-]?0X[0-9A-F]+)`
+	// pointerVerbRegexPattern: base 16 notation, with leading 0x, e.g. 0xc000012345
+	pointerVerbRegexPattern verbRegexPattern = `(0x[0-9a-fA-F]+)`
+	// wildcardVerbRegexPattern: one or more path segments, including the slashes between them, e.g. a/b/c. The
+	// repetition is lazy, so that the capture stops at the first occurrence of the literal that follows it.
+	wildcardVerbRegexPattern verbRegexPattern = `((?:[a-zA-Z0-9-._~/]|%[0-9A-Fa-f]{2})+?)`
 )
 
 // verbToRegexMapping is a mapping of verbs used in string interpolation within the fmt package and the regular
 // expressions that match them. If a particular verb does not exist in this mapping, then there are two possible reasons
 // for this:
 //
-// • The verb can be converted straight to a regex character set, e.g. d -> (\d+).
+// • The verb can be converted straight to a regex character set, e.g. w -> (\w+). Only the Perl character classes (d,
+// D, s, S, w, and W) are converted this way, any other letter is matched literally (see verbRegex).
 //
 // • The verb cannot exist within a URL without being percent-sign encoded, e.g. %q would result in the double quotes
 // being encoded to URL.
+//
+// Custom verbs can be added to this mapping using RegisterVerb.
 var verbToRegexMapping = map[string]string{
 	string(stringVerb):                  string(stringVerbRegexPattern),
 	string(boolVerb):                    string(boolVerbRegexPattern),
@@ -95,21 +174,67 @@ var verbToRegexMapping = map[string]string{
 	string(charVerb):                    string(charVerbRegexPattern),
 	string(base8Verb):                   string(base8VerbRegexPattern),
 	string(base8PrefixVerb):             string(base8PrefixVerbRegexPattern),
+	string(base10Verb):                  string(base10VerbRegexPattern),
 	string(unicodeVerb):                 string(unicodeVerbRegexPattern),
 	string(scientificNotationLowerVerb): string(scientificNotationLowerVerbRegexPattern),
 	string(scientificNotationUpperVerb): string(scientificNotationUpperVerbRegexPattern),
 	string(floatVerb):                   string(floatVerbRegexPattern),
 	string(floatSynonymVerb):            string(floatSynonymVerbRegexPattern),
-	string(floatHexLowerVerb):           string(floatHexLowerVerbRegexPattern),
-	string(floatHexUpperVerb):           string(floatHexUpperVerbRegexPattern),
+	string(generalLowerVerb):            string(generalLowerVerbRegexPattern),
+	string(generalUpperVerb):            string(generalUpperVerbRegexPattern),
+	string(hexLowerVerb):                string(hexLowerVerbRegexPattern),
+	string(hexUpperVerb):                string(hexUpperVerbRegexPattern),
+	string(base2PrefixVerb):             string(base2PrefixVerbRegexPattern),
+	string(base8ZeroPrefixVerb):         string(base8ZeroPrefixVerbRegexPattern),
+	string(hexLowerPrefixVerb):          string(hexLowerPrefixVerbRegexPattern),
+	string(hexUpperPrefixVerb):          string(hexUpperPrefixVerbRegexPattern),
+	string(pointerVerb):                 string(pointerVerbRegexPattern),
+	string(pathStringVerb):              string(pathStringVerbRegexPattern),
+	string(wildcardVerb):                string(wildcardVerbRegexPattern),
 }
 
+// verbPattern matches a string interpolation verb within a URL format. The "#" flag is kept as part of the verb, as it
+// changes the format of the verb's output, e.g. "%#x" produces a "0x" prefix. The wildcard verb "%*" is also matched,
+// which captures one or more path segments, e.g.
+//
+//	"%s://developer.valvesoftware.com/docs/%*/page"
+//
+// Will extract "a/b/c" from "https://developer.valvesoftware.com/docs/a/b/c/page". As the wildcard capture can span
+// slashes, it should always be followed by a literal (e.g. "/page") that terminates it, otherwise it will only capture
+// a single character when using Regex.
+var verbPattern = regexp.MustCompile(`%(#?[a-zA-Z]|\*)`)
+
+// missingVerbPattern matches a verb that has been filled without an arg, e.g. "%!d(MISSING)". This can occur when a
+// URL format has been passed through fmt.Sprintf without all of its args.
+var missingVerbPattern = regexp.MustCompile(`%!([a-zA-Z])\(MISSING\)`)
+
 // regexParserFunc is the signature for functions that is used in regexParsers.
 type regexParserFunc func(s string) (any, error)
 
 // regexParsers is a mapping of regular expression patterns to the function that can parse strings that match those
 // patterns.
 var regexParsers = map[string]regexParserFunc{
+	// the uninterpreted bytes of the string or slice, which are percent-decoded
+	string(stringVerbRegexPattern): func(s string) (any, error) {
+		return url.PathUnescape(s)
+	},
+	// the uninterpreted bytes of the string or slice within the path, which are percent-decoded, but "+" is kept as is
+	string(pathStringVerbRegexPattern): func(s string) (any, error) {
+		return url.PathUnescape(s)
+	},
+	// the uninterpreted bytes of the string or slice within the query, which are percent-decoded, and "+" is decoded
+	// to a space
+	string(queryStringVerbRegexPattern): func(s string) (any, error) {
+		return url.QueryUnescape(s)
+	},
+	// the uninterpreted bytes of the string or slice within the path of an opaque URI, which are percent-decoded
+	string(opaqueStringVerbRegexPattern): func(s string) (any, error) {
+		return url.PathUnescape(s)
+	},
+	// the IPv6 address between the brackets of an IPv6 literal host, which is returned as is
+	string(ipv6StringVerbRegexPattern): func(s string) (any, error) {
+		return s, nil
+	},
 	// the word true or false
 	string(boolVerbRegexPattern): func(s string) (any, error) {
 		return strconv.ParseBool(s)
@@ -120,23 +245,26 @@ var regexParsers = map[string]regexParserFunc{
 	},
 	// the character represented by the corresponding Unicode code point
 	string(charVerbRegexPattern): func(s string) (any, error) {
-		return s[0], nil
+		r, size := utf8.DecodeRuneInString(s)
+		if r == utf8.RuneError && size <= 1 {
+			return nil, fmt.Errorf("%q is not a valid UTF-8 encoded character", s)
+		}
+		return r, nil
 	},
 	// base 8
 	string(base8VerbRegexPattern): func(s string) (any, error) {
 		return strconv.ParseInt(s, 8, 64)
 	},
 	// base 8 with 0o prefix
-	string(base8PrefixVerbRegexPattern): func(s string) (any, error) {
-		return strconv.ParseInt(s, 8, 64)
-	},
+	string(base8PrefixVerbRegexPattern): parsePrefixed,
 	// base 10
 	string(base10VerbRegexPattern): func(s string) (any, error) {
 		return strconv.ParseInt(s, 10, 64)
 	},
 	// Unicode format: U+1234; same as "U+%04X"
 	string(unicodeVerbRegexPattern): func(s string) (any, error) {
-		return nil, nil
+		r, err := strconv.ParseInt(strings.TrimPrefix(s, "U+"), 16, 32)
+		return rune(r), err
 	},
 	// scientific notation, e.g. -1.234456e+78
 	string(scientificNotationLowerVerbRegexPattern): func(s string) (any, error) {
@@ -150,21 +278,57 @@ var regexParsers = map[string]regexParserFunc{
 	string(floatVerbRegexPattern): func(s string) (any, error) {
 		return strconv.ParseFloat(s, 64)
 	},
-	// hexadecimal notation (with decimal power of two exponent), e.g. -0x1.23abcp+20
-	string(floatHexLowerVerbRegexPattern): func(s string) (any, error) {
+	// the shortest representation of a float, e.g. 100000 or 1e+20
+	string(generalLowerVerbRegexPattern): func(s string) (any, error) {
 		return strconv.ParseFloat(s, 64)
 	},
-	// upper-case hexadecimal notation, e.g. -0X1.23ABCP+20
-	string(floatHexUpperVerbRegexPattern): func(s string) (any, error) {
+	// the shortest representation of a float with an upper-case exponent, e.g. 100000 or 1E+20
+	string(generalUpperVerbRegexPattern): func(s string) (any, error) {
 		return strconv.ParseFloat(s, 64)
 	},
+	// base 16, with lower-case letters for a-f, or hexadecimal notation, e.g. -0x1.23abcp+20
+	string(hexLowerVerbRegexPattern): parseHex,
+	// base 16, with upper-case letters for A-F, or upper-case hexadecimal notation, e.g. -0X1.23ABCP+20
+	string(hexUpperVerbRegexPattern): parseHex,
+	// base 2 with 0b prefix
+	string(base2PrefixVerbRegexPattern): parsePrefixed,
+	// base 8 with leading 0
+	string(base8ZeroPrefixVerbRegexPattern): parsePrefixed,
+	// base 16, with lower-case letters for a-f and 0x prefix
+	string(hexLowerPrefixVerbRegexPattern): parsePrefixed,
+	// base 16, with upper-case letters for A-F and 0X prefix
+	string(hexUpperPrefixVerbRegexPattern): parsePrefixed,
+	// one or more path segments, which are percent-decoded
+	string(wildcardVerbRegexPattern): func(s string) (any, error) {
+		return url.PathUnescape(s)
+	},
+	// base 16 notation, with leading 0x
+	string(pointerVerbRegexPattern): func(s string) (any, error) {
+		p, err := strconv.ParseUint(s, 0, 64)
+		return uintptr(p), err
+	},
+}
+
+// parsePrefixed parses a string matched by one of the base-prefixed integer verb patterns (e.g. "0x1f", "0b101",
+// "0o17", or "017"). The base is inferred from the prefix, which is stripped before parsing.
+func parsePrefixed(s string) (any, error) {
+	return strconv.ParseInt(s, 0, 64)
+}
+
+// parseHex parses a string matched by one of the hex verb patterns. Strings in hexadecimal notation (i.e. containing
+// the "0x" prefix and a power of two exponent) are parsed as a float, otherwise they are parsed as a base 16 integer.
+func parseHex(s string) (any, error) {
+	if lower := strings.ToLower(s); strings.Contains(lower, "0x") && strings.Contains(lower, "p") {
+		return strconv.ParseFloat(s, 64)
+	}
+	return strconv.ParseInt(s, 16, 64)
 }
 
 type protocol string
 
 const (
 	fmtProtocol   protocol = "%s://"
-	regexProtocol protocol = "https?://"
+	regexProtocol protocol = "(?:https?:)?//"
 	httpProtocol  protocol = "http://"
 	httpsProtocol protocol = "https://"
 	noProtocol    protocol = ""
@@ -177,6 +341,10 @@ var protocols = []protocol{
 	httpsProtocol,
 }
 
+// schemePattern matches a "scheme://" prefix at the beginning of a URL format, where the scheme follows the syntax
+// defined by RFC 3986. The first group is the scheme.
+var schemePattern = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*)://`)
+
 func (p protocol) hasProtocol(u URL) bool {
 	return strings.HasPrefix(string(u), string(p))
 }
@@ -189,8 +357,114 @@ func (p protocol) replaceProtocol(u URL, new protocol) string {
 // fetching. The protocol should be given as a string verb at the beginning of the URL.
 type URL string
 
-// withProtocol replaces the URL(s) current protocol with the given protocol.
+// New creates a URL from the given format, after trimming any surrounding whitespace and checking the format using
+// Validate. This is a safer alternative to converting a string to a URL directly, as malformed formats are caught up
+// front rather than when the URL is first used.
+func New(format string) (URL, error) {
+	u := URL(strings.TrimSpace(format))
+	if u == "" {
+		return "", errors.New("URL format is empty")
+	}
+	if err := u.Validate(); err != nil {
+		return "", err
+	}
+	return u, nil
+}
+
+// MustNew is the same as New, except that it panics if the format is malformed. This is intended for initialising
+// package level variables.
+func MustNew(format string) URL {
+	u, err := New(format)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// relative returns whether the URL format is a relative reference that begins with a path, e.g. "/app/%d/reviews",
+// rather than a full URL. Relative URL formats are never given a protocol.
+func (u URL) relative() bool {
+	return strings.HasPrefix(string(u), "/") && !strings.HasPrefix(string(u), "//")
+}
+
+// WithBase resolves the relative URL format (see relative) against the given base URL, returning an absolute URL format
+// that can be used to fill and match absolute URLs. This allows the host to be configured separately from the path of
+// a URL format, e.g.
+//
+//	const AppPage URL = "/app/%d"
+//	steamAppPage, err := AppPage.WithBase("https://store.steampowered.com")
+//	steamAppPage.Match("https://store.steampowered.com/app/477160") // true
+//
+// As relative URL formats always begin with a path, the path, query, and fragment of the base URL are replaced, as they
+// would be by url.URL.ResolveReference. The scheme of the base URL is pinned (see pinnedScheme), so the returned URL
+// format only fills and matches URLs with that scheme. URL formats that are not relative are returned as is. An error
+// is returned if the base URL cannot be parsed, or is not absolute.
+func (u URL) WithBase(base string) (URL, error) {
+	if !u.relative() {
+		return u, nil
+	}
+
+	parsed, err := url.Parse(base)
+	if err != nil {
+		return u, errors.Wrapf(err, "could not parse base URL %q", base)
+	}
+	if !parsed.IsAbs() || parsed.Host == "" {
+		return u, fmt.Errorf("base URL %q must have a scheme and a host", base)
+	}
+
+	root := strings.TrimSuffix(parsed.ResolveReference(&url.URL{Path: "/"}).String(), "/")
+	return URL(strings.ReplaceAll(root, "%", "%%") + string(u)), nil
+}
+
+// pinnedScheme returns the scheme that is pinned at the beginning of the URL format, e.g. "http" for "http://%s/path",
+// "ftp" for "ftp://%s/path", or an opaque scheme (see opaqueSchemes), e.g. "mailto" for "mailto:%s". An empty string is
+// returned otherwise. URL formats with a pinned scheme, including a literal "http" or "https" scheme, keep that scheme
+// when filled and matched, rather than having it replaced by the protocol verb. The "%s://" protocol marker should be
+// used instead to fill and match both "http" and "https".
+func (u URL) pinnedScheme() string {
+	groups := schemePattern.FindStringSubmatch(string(u))
+	if groups == nil {
+		return opaqueScheme(string(u))
+	}
+	return groups[1]
+}
+
+// opaqueSchemes are the schemes that are recognised at the beginning of a URL format without a following "//", e.g.
+// "mailto:%s" or "tel:%s". Opaque URIs have no authority or hierarchical path, so a URL format beginning with one of
+// these schemes is never given a protocol. A list is used, rather than recognising any "scheme:" prefix, as a URL
+// format without a protocol may begin with a host and port, e.g. "localhost:%d/app/%d".
+var opaqueSchemes = map[string]bool{
+	"data":   true,
+	"geo":    true,
+	"magnet": true,
+	"mailto": true,
+	"news":   true,
+	"sms":    true,
+	"tel":    true,
+	"urn":    true,
+}
+
+// opaqueScheme returns the opaque scheme (see opaqueSchemes) at the beginning of the given format, e.g. "mailto" for
+// "mailto:%s". An empty string is returned if the format does not begin with an opaque scheme.
+func opaqueScheme(format string) string {
+	if end := strings.IndexByte(format, ':'); end > 0 && opaqueSchemes[strings.ToLower(format[:end])] {
+		return format[:end]
+	}
+	return ""
+}
+
+// hasProtocolVerb returns whether the un-formatted URL (see String) begins with the "%s://" protocol marker, i.e. the
+// URL format is neither relative nor has a pinned scheme (see pinnedScheme).
+func (u URL) hasProtocolVerb() bool {
+	return !u.relative() && u.pinnedScheme() == ""
+}
+
+// withProtocol replaces the URL(s) current protocol with the given protocol. Relative URL formats, and URL formats
+// with a pinned scheme (see pinnedScheme), are returned as is.
 func (u URL) withProtocol(p protocol) string {
+	if !u.hasProtocolVerb() {
+		return string(u)
+	}
 	foundProtocol := noProtocol
 	for _, checkedProtocol := range protocols {
 		if checkedProtocol.hasProtocol(u) {
@@ -204,204 +478,1979 @@ func (u URL) withProtocol(p protocol) string {
 //
 //	"%s://"
 //
-// Replacing an existing protocol, if there is one already, or adding one on if there isn't one.
+// Replacing an existing protocol, if there is one already, or adding one on if there isn't one. Relative URL formats
+// that begin with a path (e.g. "/app/%d/reviews") are returned without a protocol, and URL formats that begin with a
+// scheme, including "http" and "https" (e.g. "http://%s/path" or "ftp://%s/path"), keep that scheme (see pinnedScheme).
 func (u URL) String() string {
 	return u.withProtocol(fmtProtocol)
 }
 
 // Fill will apply string interpolation to the URL. The protocol does not need to be included as "https" is always
-// prepended to the args.
+// prepended to the args, unless the URL format is relative (e.g. "/app/%d/reviews") or begins with a literal scheme
+// (e.g. "ftp://%s/path"), in which case the args are passed straight through. This includes a literal "http" or "https"
+// scheme, which is kept rather than replaced, e.g. "http://host/app/%d" filled with 477160 produces
+// "http://host/app/477160" (see pinnedScheme). Any repeated query parameter markers (e.g. "{tags...}") are expanded
+// using the slice arg in their position. Optional sections (e.g. "%{:%d%}") are omitted when all the args for the verbs
+// within them are nil.
 func (u URL) Fill(args ...any) string {
-	args = append([]any{"https"}, args...)
-	return fmt.Sprintf(u.String(), args...)
+	if u.hasProtocolVerb() {
+		args = append([]any{"https"}, args...)
+	}
+	format, args := fillOptional(u.String(), args)
+	format, args = expandRepeated(format, args)
+	return fmt.Sprintf(fmtFormat(format), args...)
 }
 
-// Regex converts the URL to a regex by replacing the string interpolation verbs with their regex character set
-// counterparts.
-func (u URL) Regex() *regexp.Regexp {
-	protocolString := regexp.MustCompile("%!([a-zA-Z])\\(MISSING\\)").ReplaceAllString(u.withProtocol(regexProtocol), "%$1")
-	return regexp.MustCompile(regexp.MustCompile("%([a-zA-Z])").ReplaceAllStringFunc(protocolString, func(s string) string {
-		var ok bool
-		charSet := strings.ReplaceAll(s, "%", "")
-		if s, ok = verbToRegexMapping[charSet]; !ok {
-			s = fmt.Sprintf(`(\%s+)`, charSet)
+// verbKinds is a mapping of verbs to the reflect.Kind of the value that the parser for the verb's regex pattern (see
+// regexParsers) produces. Verbs that do not exist within this mapping produce a reflect.String.
+var verbKinds = map[string]reflect.Kind{
+	string(boolVerb):                    reflect.Bool,
+	string(base2Verb):                   reflect.Int64,
+	string(charVerb):                    reflect.Int32,
+	string(base8Verb):                   reflect.Int64,
+	string(base8PrefixVerb):             reflect.Int64,
+	string(base10Verb):                  reflect.Int64,
+	string(unicodeVerb):                 reflect.Int32,
+	string(scientificNotationLowerVerb): reflect.Float64,
+	string(scientificNotationUpperVerb): reflect.Float64,
+	string(floatVerb):                   reflect.Float64,
+	string(floatSynonymVerb):            reflect.Float64,
+	string(generalLowerVerb):            reflect.Float64,
+	string(generalUpperVerb):            reflect.Float64,
+	string(hexLowerVerb):                reflect.Int64,
+	string(hexUpperVerb):                reflect.Int64,
+	string(base2PrefixVerb):             reflect.Int64,
+	string(base8ZeroPrefixVerb):         reflect.Int64,
+	string(hexLowerPrefixVerb):          reflect.Int64,
+	string(hexUpperPrefixVerb):          reflect.Int64,
+	string(pointerVerb):                 reflect.Uintptr,
+}
+
+// VerbInfo describes a string interpolation verb within a URL format.
+type VerbInfo struct {
+	// Verb is the letter of the verb, e.g. "d", including the "#" flag if given, e.g. "#x". For a repeated query
+	// parameter marker this is the marker itself, e.g. "{tags...}".
+	Verb string
+	// Offset is the byte offset of the verb's "%" within the un-formatted URL (see URL.String).
+	Offset int
+	// Pattern is the regex pattern that the verb is converted to by URL.Regex.
+	Pattern string
+	// Kind is the reflect.Kind of the value that URL.ExtractArgs will produce for the verb. The hex verbs (x and X) are
+	// reported as reflect.Int64, but will produce a reflect.Float64 when matching hexadecimal float notation. Repeated
+	// query parameter markers are reported as reflect.Slice, as they produce a []string. Custom verbs registered with a
+	// parser (see RegisterVerb) are reported as reflect.Interface.
+	Kind reflect.Kind
+	// Optional is true if the verb is within an optional section (see URL.Fill), and so may be extracted as nil.
+	Optional bool
+}
+
+// Verbs returns information on each of the string interpolation verbs within the URL format, in the order in which
+// they appear, excluding the verb for the protocol. This can be used to validate args against the expected types before
+// calling Fill.
+func (u URL) Verbs() []VerbInfo {
+	format := u.String()
+	locs := findTokens(format)
+	verbs := make([]VerbInfo, 0, len(locs))
+	for i, loc := range locs {
+		if i == 0 && u.hasProtocolVerb() {
+			// Skip the protocol verb
+			continue
+		}
+
+		if loc[4] >= 0 {
+			verbs = append(verbs, VerbInfo{
+				Verb:     format[loc[0]:loc[1]],
+				Offset:   loc[0],
+				Pattern:  repeatedRegexPattern(format[loc[4]:loc[5]]),
+				Kind:     reflect.Slice,
+				Optional: optional(format, loc[0]),
+			})
+			continue
 		}
-		return s
-	}))
+
+		verb := format[loc[2]:loc[3]]
+		verbs = append(verbs, VerbInfo{
+			Verb:     verb,
+			Offset:   loc[0],
+			Pattern:  verbRegexAt(format, loc[0], verb),
+			Kind:     verbKind(verb),
+			Optional: optional(format, loc[0]),
+		})
+	}
+	return verbs
 }
 
-// Match the given URL with a URL to check if they are the same format.
-func (u URL) Match(url string) bool {
-	return u.Regex().MatchString(url)
+// NumVerbs returns the number of string interpolation verbs within the URL format, excluding the verb for the
+// protocol. This is the number of args that should be given to Fill.
+func (u URL) NumVerbs() int {
+	return len(u.Verbs())
 }
 
-// ExtractArgs extracts the necessary arguments from the given URL to run the ScrapeURL.Soup, URL.JSON, and
-// URL.Fill methods. This is useful when taking a URL matched by URL.Match and fetching the soup for that
-// matched URL.
-func (u URL) ExtractArgs(url string) (args []any) {
-	pattern := u.Regex()
-	metaPattern := regexp.MustCompile(`(?m)(\([^()]+?\))`)
-	groups := pattern.FindStringSubmatch(url)[1:]
-	groupPatterns := make([]string, 0)
-	for _, groupMatches := range metaPattern.FindAllStringSubmatch(pattern.String(), -1) {
-		groupPatterns = append(groupPatterns, groupMatches[1:][0])
-	}
-	if len(groups) != len(groupPatterns) {
-		panic(fmt.Errorf(
-			"the number of groups matched by %s doesn't match the number of groups found in the pattern (%d vs %d)",
-			pattern.String(), len(groups), len(groupPatterns),
-		))
-	}
-	args = make([]any, len(groups))
-	for i, group := range groups {
-		groupPattern := groupPatterns[i]
-		if parseFunc, ok := regexParsers[groupPattern]; ok {
-			var err error
-			if args[i], err = parseFunc(group); err != nil {
-				panic(errors.Wrapf(err, "could not parse string %q using parser for %q", group, groupPattern))
+// GroupInfo returns the number of string interpolation verbs within the URL format (see NumVerbs), as well as the
+// source of each capturing group within the regex produced by Regex, in the order in which they are captured. This is
+// the same information that ExtractArgs uses to parse each matched group, so it can be used to check that a URL format
+// will extract as many args as it has verbs before calling ExtractArgs, or to diagnose an ErrGroupCountMismatch.
+// Unlike Regex, GroupInfo does not compile the regex, so it will not panic for an invalid URL format.
+func (u URL) GroupInfo() (verbCount int, groupPatterns []string) {
+	return u.NumVerbs(), captureGroups(u.regexSource())
+}
+
+// signatureReplacer replaces the escaped percent signs and the markers of optional sections within the literal text of a
+// URL format for Signature.
+var signatureReplacer = strings.NewReplacer("%%", "%", optionalOpen, "[", optionalClose, "]")
+
+// Signature returns a human-friendly description of the URL format, where each string interpolation verb is replaced by
+// the verb within braces, and the protocol verb is replaced by "{scheme}". For example, the signature of
+// "%s://store.steampowered.com/app/%d" is:
+//
+//	"{scheme}://store.steampowered.com/app/{d}"
+//
+// Repeated query parameter markers (e.g. "{tags...}") are kept as is, optional sections are wrapped in square brackets,
+// and escaped percent signs are unescaped. Relative URL formats, and URL formats with a pinned scheme (e.g.
+// "ftp://%s/pub"), have no "{scheme}".
+func (u URL) Signature() string {
+	format := u.String()
+	var b strings.Builder
+	last := 0
+	for i, loc := range findTokens(format) {
+		b.WriteString(signatureReplacer.Replace(format[last:loc[0]]))
+		switch {
+		case i == 0 && u.hasProtocolVerb():
+			b.WriteString("{scheme}")
+		case loc[4] >= 0:
+			b.WriteString(format[loc[0]:loc[1]])
+		default:
+			b.WriteString("{" + format[loc[2]:loc[3]] + "}")
+		}
+		last = loc[1]
+	}
+	b.WriteString(signatureReplacer.Replace(format[last:]))
+	return b.String()
+}
+
+// ArgTypes returns the reflect.Kind of each arg that should be given to Fill, in order, excluding the protocol. These
+// are the same kinds as the values that ExtractArgs produces (see VerbInfo.Kind).
+func (u URL) ArgTypes() []reflect.Kind {
+	verbs := u.Verbs()
+	kinds := make([]reflect.Kind, len(verbs))
+	for i, verb := range verbs {
+		kinds[i] = verb.Kind
+	}
+	return kinds
+}
+
+// Partial partially applies the given args to the URL format, returning a new URL format with the leading verbs (not
+// including the protocol) replaced by the given args. The remaining verbs are left intact so that the returned URL can
+// be filled with the rest of the args later. Any "%" within a substituted arg is escaped to "%%" so that the returned
+// URL remains a valid format. If more args are given than there are verbs, then the extra args are ignored.
+//
+//	base := SteamAppReviews.Partial(477160)
+//	base.Fill("*", 20)
+func (u URL) Partial(args ...any) URL {
+	format := u.String()
+	locs := findTokens(format)
+	if len(locs) > 0 && u.hasProtocolVerb() {
+		// Skip the protocol verb
+		locs = locs[1:]
+	}
+
+	var b strings.Builder
+	last := 0
+	for i, loc := range locs {
+		if i >= len(args) {
+			break
+		}
+		b.WriteString(format[last:loc[0]])
+		b.WriteString(strings.ReplaceAll(fillToken(format[loc[0]:loc[1]], args[i]), "%", "%%"))
+		last = loc[1]
+	}
+	b.WriteString(format[last:])
+	return URL(b.String())
+}
+
+// FillValidated is the same as Fill, but will return an error if the number of args given does not match the number
+// of verbs within the URL format (see NumVerbs). This avoids malformed URLs containing "%!d(MISSING)" or
+// "%!(EXTRA ...)" from being produced.
+func (u URL) FillValidated(args ...any) (string, error) {
+	if numVerbs := u.NumVerbs(); len(args) != numVerbs {
+		return "", fmt.Errorf("%s expects %d args (excluding the protocol), but %d were given", u.String(), numVerbs, len(args))
+	}
+	return u.Fill(args...), nil
+}
+
+// FillAll fills the URL format once for each of the given arg sets, returning the filled URLs in the same order. This
+// is useful for generating a crawl frontier from a list of IDs, for example, and is the complement of ExtractBatch.
+// Each arg set is checked before it is filled, and an error is returned for the first arg set that either has the
+// wrong number of args (see FillValidated), or has an arg whose type cannot fill its verb (see FillStruct), e.g. a
+// string for a "%d" verb. The error includes the index of the arg set. Nil args are allowed, so that optional sections
+// can be omitted.
+func (u URL) FillAll(argSets [][]any) ([]string, error) {
+	verbs := u.Verbs()
+	urls := make([]string, len(argSets))
+	for i, args := range argSets {
+		if len(args) != len(verbs) {
+			return nil, fmt.Errorf("arg set %d: %s expects %d args (excluding the protocol), but %d were given", i, u.String(), len(verbs), len(args))
+		}
+		for j, arg := range args {
+			if arg != nil && !fillableBy(reflect.ValueOf(arg), verbs[j]) {
+				return nil, fmt.Errorf("arg set %d: arg %d of type %T cannot fill the %%%s verb of %s", i, j, arg, verbs[j].Verb, u.String())
 			}
-		} else {
-			args[i] = group
 		}
+		urls[i] = u.Fill(args...)
 	}
-	return args
+	return urls, nil
 }
 
-// Standardise will first extract the args from the given URL then Fill the referred to URL with those args.
-func (u URL) Standardise(url string) string {
-	args := u.ExtractArgs(url)
+// exampleArgs are the placeholder args that are used to fill each verb of a URL format within Example. Verbs that are
+// not in this mapping, such as custom verbs (see RegisterVerb), or whose placeholder does not match the regex for the
+// verb at its position, are filled with the first of sampleArgs that matches instead.
+var exampleArgs = map[string]string{
+	string(stringVerb):                  "example",
+	string(boolVerb):                    "true",
+	string(base2Verb):                   "101",
+	string(charVerb):                    "a",
+	string(base8Verb):                   "17",
+	string(base8PrefixVerb):             "0o17",
+	string(base10Verb):                  "123",
+	string(unicodeVerb):                 "U+0041",
+	string(scientificNotationLowerVerb): "1.5e+06",
+	string(scientificNotationUpperVerb): "1.5E+06",
+	string(floatVerb):                   "1.5",
+	string(floatSynonymVerb):            "1.5",
+	string(generalLowerVerb):            "1.5",
+	string(generalUpperVerb):            "1.5",
+	string(hexLowerVerb):                "ff",
+	string(hexUpperVerb):                "FF",
+	string(base2PrefixVerb):             "0b101",
+	string(base8ZeroPrefixVerb):         "017",
+	string(hexLowerPrefixVerb):          "0xff",
+	string(hexUpperPrefixVerb):          "0XFF",
+	string(pathStringVerb):              "example",
+	string(pointerVerb):                 "0xc000012345",
+	string(wildcardVerb):                "example/path",
+}
+
+// Example returns a plausible URL for the URL format, where each verb is filled with a placeholder arg appropriate for
+// its type (see exampleArgs), e.g. "%s://store.steampowered.com/app/%d" produces
+// "https://store.steampowered.com/app/123". This is useful for documentation, fuzz seeds, and tests that need a
+// concrete URL that matches the URL format. Optional sections are included, and repeated query parameter markers are
+// filled with a single value. The placeholder for a verb is checked against the regex for the verb at its position in
+// the URL format, so that the returned URL matches the URL format wherever possible. Example never panics.
+func (u URL) Example() string {
+	return sampleURL(u, func(verb string, pattern *regexp.Regexp) string {
+		placeholder, ok := exampleArgs[verb]
+		if ok && pattern.MatchString(placeholder) {
+			return placeholder
+		}
+		for _, arg := range sampleArgs {
+			if pattern.MatchString(arg) {
+				return arg
+			}
+		}
+		if ok {
+			return placeholder
+		}
+		return "example"
+	})
+}
+
+// FilledURL returns the URL that GetRequest, Request, and the other request helpers would fetch for the given args,
+// without constructing an http.Request. This is useful for logging, or for passing the URL to another client.
+func (u URL) FilledURL(args ...any) string {
 	return u.Fill(args...)
 }
 
-// GetRequest creates a new http.MethodGet http.Request for the given URL with the given arguments.
-func (u URL) GetRequest(args ...any) (url string, req *http.Request, err error) {
-	url = u.Fill(args...)
-	if req, err = http.NewRequest(http.MethodGet, url, nil); err != nil {
-		err = errors.Wrapf(err, "request for %q could not be created", url)
+// MustFill is the same as FillValidated, except that it panics if the number of args given does not match the number of
+// verbs within the URL format. This is intended for tests and for initialising package level variables.
+func (u URL) MustFill(args ...any) string {
+	filled, err := u.FillValidated(args...)
+	if err != nil {
+		panic(err)
 	}
-	return
+	return filled
 }
 
-// Request creates a new http.Request for the given URL with the given arguments, method, and io.Reader.
-func (u URL) Request(method string, body io.Reader, args ...any) (url string, req *http.Request, err error) {
-	url = u.Fill(args...)
-	if req, err = http.NewRequest(method, url, body); err != nil {
-		err = errors.Wrapf(err, "request for %q could not be created", url)
+// FillURL is the same as Fill, except that the filled URL is parsed into a *url.URL, giving structured access to the
+// host, path, and query. An error is returned if the filled URL cannot be parsed.
+func (u URL) FillURL(args ...any) (*url.URL, error) {
+	filled := u.Fill(args...)
+	parsed, err := url.Parse(filled)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse %q filled from %s", filled, u.String())
 	}
-	return
+	return parsed, nil
 }
 
-// Soup fetches the URL using the default HTTP client, then parses the returned HTML page into a soup.Root. It
-// also returns the http.Response object returned by the http.Get request. A http.Request can be provided, but if nil is
-// provided then a default http.MethodGet http.Request will be constructed instead.
-func (u URL) Soup(req *http.Request, args ...any) (doc *soup.Root, resp *http.Response, err error) {
-	if req == nil {
-		if _, req, err = u.GetRequest(args...); err != nil {
-			return
+// FillCanonical is the same as Fill, except that the query parameters of the filled URL are sorted by key and
+// re-encoded using url.Values.Encode, so that URL formats which only differ by the order of their query parameters
+// produce identical URLs. This is useful for generating cache keys. The rest of the filled URL, including the path and
+// fragment, is left exactly as is. Unlike Normalize, no args are extracted. An error is returned if the query of the
+// filled URL cannot be parsed.
+func (u URL) FillCanonical(args ...any) (string, error) {
+	filled := u.Fill(args...)
+	rest, fragment, hasFragment := strings.Cut(filled, "#")
+	canonical, query, hasQuery := strings.Cut(rest, "?")
+	if hasQuery {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return "", errors.Wrapf(err, "could not parse the query of %q filled from %s", filled, u.String())
 		}
+		canonical += "?" + values.Encode()
 	}
+	if hasFragment {
+		canonical += "#" + fragment
+	}
+	return canonical, nil
+}
 
-	if resp, err = http.DefaultClient.Do(req); err != nil {
-		err = errors.Wrapf(err, "could not get Steam page %s", req.URL.String())
-		return
+// FillWithQuery is the same as Fill, except that the given extra query parameters are merged into the query of the
+// filled URL. Extra query parameters override any query parameters of the same key within the URL format, whereas the
+// rest of the query parameters within the URL format are kept. The query is re-encoded using url.Values.Encode, so the
+// query parameters of the returned URL are sorted by key. An error is returned if the filled URL cannot be parsed.
+func (u URL) FillWithQuery(extra url.Values, args ...any) (string, error) {
+	parsed, err := u.FillURL(args...)
+	if err != nil {
+		return "", err
 	}
 
-	if resp.Body != nil {
-		defer func(body io.ReadCloser) {
-			err = agem.MergeErrors(err, errors.Wrapf(body.Close(), "could not close response body to %s", req.URL.String()))
-		}(resp.Body)
+	query := parsed.Query()
+	for key, values := range extra {
+		query[key] = values
 	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
 
-	var body []byte
-	if body, err = io.ReadAll(resp.Body); err != nil {
-		err = errors.Wrapf(err, "could not read response body to %s", req.URL.String())
-		return
+// FillWithDefaults is the same as Fill, except that any nil arg is replaced by the default at the same index within the
+// given defaults before the URL is filled. This saves call sites from having to choose between an arg and its default:
+//
+//	const SteamSearch URL = "%s://store.steampowered.com/search?term=%s&page=%d"
+//	SteamSearch.FillWithDefaults([]any{nil, 1}, "portal", nil) // https://store.steampowered.com/search?term=portal&page=1
+//
+// The defaults are aligned with the args given to Fill, so, like the args, they should not include the implicit
+// protocol arg. A nil arg is left as is if there is no default at its index, or if its default is also nil. Note that
+// this means that the verbs of an optional section (see Fill) that have a non-nil default will always be filled.
+func (u URL) FillWithDefaults(defaults []any, args ...any) string {
+	filled := make([]any, len(args))
+	for i, arg := range args {
+		if arg == nil && i < len(defaults) {
+			arg = defaults[i]
+		}
+		filled[i] = arg
 	}
+	return u.Fill(filled...)
+}
 
-	root := soup.HTMLParse(string(body))
-	doc = &root
-	return
+// Regex converts the URL to a regex by replacing the string interpolation verbs with their regex character set
+// counterparts. Any literal text within the URL format is escaped so that characters such as "?" and "." are matched
+// literally. The scheme of the protocol is optional, so protocol-relative URLs (e.g. "//store.steampowered.com/app/1"),
+// which are common within the "href" and "src" attributes of scraped HTML, are matched as well. The protocol is never
+// captured, so the args extracted from a protocol-relative URL are the same as those extracted from an absolute URL.
+// Regex will panic if the URL format produces an invalid regex, use RegexErr to handle the error instead.
+func (u URL) Regex() *regexp.Regexp {
+	pattern, err := u.RegexErr()
+	if err != nil {
+		panic(err)
+	}
+	return pattern
 }
 
-// RetrySoup will run Soup with the given args and try the given function. If the function returns an error then the
-// function will be retried up to a total of the given number of maxTries. If minDelay is given, and is not 0, then
-// before the function is retried it will sleep for (maxTries + 1 - currentTries) * minDelay. If a non-nil http.Request
-// is provided then it will be used to fetch the page for the Soup, otherwise a default http.MethodGet http.Request will
-// be constructed instead.
-func (u URL) RetrySoup(req *http.Request, maxTries int, minDelay time.Duration, try func(doc *soup.Root, resp *http.Response) error, args ...any) error {
-	return agem.Retry(maxTries, minDelay, func(currentTry int, maxTries int, minDelay time.Duration, args ...any) (err error) {
-		var (
-			doc  *soup.Root
-			resp *http.Response
-		)
-		if doc, resp, err = u.Soup(req, args...); err != nil {
-			return errors.Wrapf(err, "ran out of tries (%d total) whilst requesting Soup for %s", maxTries, u.String())
-		}
-		if err = try(doc, resp); err != nil {
-			return errors.Wrapf(err, "ran out of tries (%d total) whilst calling try function for %s", maxTries, u.String())
+// RegexErr is the same as Regex, except that an error is returned if the URL format produces an invalid regex, rather
+// than panicking.
+func (u URL) RegexErr() (*regexp.Regexp, error) {
+	pattern, err := regexp.Compile(u.regexSource())
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s does not produce a valid regex", u.String())
+	}
+	return pattern, nil
+}
+
+// verbRegexAt returns the regex pattern for the given verb at the given offset within the given format. String verbs
+// within the query of the format are allowed to match "+", which is decoded to a space when extracted. String verbs
+// that are the only thing between a pair of brackets, e.g. "%s://[%s]:%d/path", are treated as IPv6 literal hosts and
+// so are allowed to match colons. String verbs within the path of an opaque URI, e.g. "mailto:%s", are allowed to match
+// "@" and the other sub-delimiters. A string verb that directly follows the "?" at the very end of the format, e.g.
+// "%s://itch.io/search?%s", captures the entire raw query, including "&" and "=", rather than a single value.
+func verbRegexAt(format string, offset int, verb string) string {
+	pattern, _ := verbRegex(verb)
+	if pattern != string(stringVerbRegexPattern) {
+		return pattern
+	}
+
+	if end := offset + len("%") + len(verb); offset > 0 && format[offset-1] == '?' && end == len(format) {
+		return string(rawQueryStringVerbRegexPattern)
+	}
+
+	if end := offset + len("%") + len(verb); offset > 0 && format[offset-1] == '[' && strings.HasPrefix(format[end:], "]") {
+		return string(ipv6StringVerbRegexPattern)
+	}
+
+	query, fragment := strings.IndexByte(format, '?'), strings.IndexByte(format, '#')
+	if query >= 0 && query < offset && (fragment < query || fragment > offset) {
+		return string(queryStringVerbRegexPattern)
+	}
+	if opaqueScheme(format) != "" && (fragment < 0 || fragment > offset) {
+		return string(opaqueStringVerbRegexPattern)
+	}
+	return pattern
+}
+
+// regexSource returns the source of the unanchored regex for the URL format.
+func (u URL) regexSource() string {
+	format := missingVerbPattern.ReplaceAllString(u.withProtocol(noProtocol), "%$1")
+	var b strings.Builder
+	if u.hasProtocolVerb() {
+		b.WriteString(string(regexProtocol))
+	}
+	last := 0
+	for _, loc := range findTokens(format) {
+		b.WriteString(quoteLiteral(format[last:loc[0]]))
+		if loc[4] >= 0 {
+			b.WriteString(repeatedRegexPattern(format[loc[4]:loc[5]]))
+			last = loc[1]
+			continue
 		}
-		return nil
-	}, args...)
+		b.WriteString(verbRegexAt(format, loc[0], format[loc[2]:loc[3]]))
+		last = loc[1]
+	}
+	b.WriteString(quoteLiteral(format[last:]))
+	return b.String()
 }
 
-// JSON makes a request to the URL and parses the response to JSON. As well as returning the parsed JSON as a map,
-// it also returns the response to the original HTTP request made to the given URL. If a non-nil http.Request is
-// provided then it will be used to fetch the JSON resource, otherwise default http.MethodGet http.Request will be
-// constructed instead.
-func (u URL) JSON(req *http.Request, args ...any) (jsonBody map[string]any, resp *http.Response, err error) {
-	client := http.Client{Timeout: time.Second * 10}
-	if req == nil {
-		if _, req, err = u.GetRequest(args...); err != nil {
-			return
+// Validate checks the URL format for problems up front, rather than waiting for Regex to panic on first use. An error
+// describing the first problem found is returned. The following are checked:
+//
+// • Each "%" is either escaped as "%%", or is followed by a verb that can be converted to a regex (see Regex).
+//
+// • Optional sections (e.g. "%{:%d%}") are balanced and are not nested.
+//
+// • The protocol, if there is one, is either the "%s://" protocol marker or a valid "scheme://" prefix (e.g. "https://"
+// or "ftp://"), and is at the very beginning of the URL format. The protocol marker must not be malformed, e.g. "%s:/".
+//
+// • The URL format produces a valid regex.
+func (u URL) Validate() error {
+	format := string(u)
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if strings.HasPrefix(format[i+1:], "%") || strings.HasPrefix(format[i:], optionalOpen) || strings.HasPrefix(format[i:], optionalClose) {
+			i++
+			continue
 		}
+
+		loc := verbPattern.FindStringSubmatchIndex(format[i:])
+		if loc == nil || loc[0] != 0 {
+			return fmt.Errorf("%s contains a stray %% at offset %d that is not followed by a verb", format, i)
+		}
+		if verb := format[i+loc[2] : i+loc[3]]; !knownVerb(verb) {
+			return fmt.Errorf("%s contains the verb %%%s at offset %d that cannot be converted to a regex", format, verb, i)
+		}
+		i += loc[1] - 1
 	}
 
-	if resp, err = client.Do(req); err != nil {
-		err = errors.Wrapf(err, "JSON could not be fetched from \"%s\"", req.URL.String())
+	if err := validateOptional(format); err != nil {
+		return err
+	}
+
+	if start := strings.Index(format, "://"); start >= 0 && !strings.ContainsAny(format[:start], "/?#") {
+		if scheme := format[:start]; scheme != "%s" && !schemePattern.MatchString(format) {
+			return fmt.Errorf("%s has the protocol %q, which is neither %q nor a valid scheme", format, scheme, "%s")
+		}
+	}
+	if strings.HasPrefix(format, "%s//") || (strings.HasPrefix(format, "%s:/") && !strings.HasPrefix(format, string(fmtProtocol))) {
+		return fmt.Errorf("%s begins with a malformed protocol marker, it should begin with %q", format, fmtProtocol)
+	}
+	if start := strings.Index(format, string(fmtProtocol)); start > 0 {
+		return fmt.Errorf("%s contains the protocol marker %q at offset %d, rather than at the beginning", format, fmtProtocol, start)
+	}
+
+	_, err := u.RegexErr()
+	return err
+}
+
+// RegexExact is the same as Regex, except that the regex is anchored to both the start and the end of the input. A
+// single trailing slash is tolerated, unless the URL format itself ends with a slash, so that both "/app/477160" and
+// "/app/477160/" will match "/app/%d".
+func (u URL) RegexExact() (*regexp.Regexp, error) {
+	source := "^" + u.regexSource()
+	if !strings.HasSuffix(string(u), "/") {
+		source += "/?"
+	}
+	pattern, err := regexp.Compile(source + "$")
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s does not produce a valid exact regex", u.String())
+	}
+	return pattern, nil
+}
+
+// MatchExact checks whether the entirety of the given URL matches the URL format, unlike Match which will find a
+// match anywhere within the given URL. See RegexExact for more info.
+func (u URL) MatchExact(url string) bool {
+	pattern, err := u.RegexExact()
+	if err != nil {
+		return false
+	}
+	return pattern.MatchString(u.candidate(url))
+}
+
+// ExtractArgsExact is the same as ExtractArgsErr, except that the entirety of the given URL must match the URL format.
+// See RegexExact for more info.
+func (u URL) ExtractArgsExact(url string) (args []any, err error) {
+	var pattern *regexp.Regexp
+	if pattern, err = u.RegexExact(); err != nil {
 		return
 	}
+	return u.extract(pattern, url)
+}
 
-	if resp.Body != nil {
-		defer func(Body io.ReadCloser) {
-			err = agem.MergeErrors(err, errors.Wrapf(
-				Body.Close(),
-				"request body for JSON fetched from \"%s\" could not be closed",
-				req.URL.String(),
-			))
-		}(resp.Body)
+// bareProtocol is the regex that replaces regexProtocol within RegexBare, which makes the scheme and the "//" optional.
+const bareProtocol = "(?:(?:https?:)?//)?"
+
+// RegexBare is the same as Regex, except that the regex is anchored to the start of the input, and the scheme and "//"
+// of the protocol are optional. This allows bare authority strings without a protocol, which are common within scraped
+// text and configuration, to be matched. For example, "%s://store.steampowered.com/app/%d" will match all of:
+//
+//	"store.steampowered.com/app/477160"
+//	"//store.steampowered.com/app/477160"
+//	"https://store.steampowered.com/app/477160"
+//
+// The protocol is never captured, so the args extracted from a bare input are the same as those extracted from an
+// absolute URL. For relative URL formats, and URL formats with a pinned scheme (see pinnedScheme), which have no
+// protocol verb, this is the same as Regex but anchored to the start of the input.
+func (u URL) RegexBare() (*regexp.Regexp, error) {
+	source := u.regexSource()
+	if u.hasProtocolVerb() {
+		source = bareProtocol + strings.TrimPrefix(source, string(regexProtocol))
 	}
+	pattern, err := regexp.Compile(`\A` + source)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s does not produce a valid bare regex", u.String())
+	}
+	return pattern, nil
+}
 
-	var body []byte
-	if body, err = io.ReadAll(resp.Body); err != nil {
-		err = errors.Wrapf(err, "JSON request body from \"%s\" could not be read", req.URL.String())
+// MatchBare checks whether the given input matches the URL format, where the input may omit the scheme and "//" of
+// the protocol entirely, e.g. "store.steampowered.com/app/477160". See RegexBare for more info.
+func (u URL) MatchBare(input string) bool {
+	pattern, err := u.RegexBare()
+	if err != nil {
+		return false
+	}
+	return pattern.MatchString(u.candidate(input))
+}
+
+// ExtractArgsBare is the same as ExtractArgsErr, except that the given input may omit the scheme and "//" of the
+// protocol entirely. See RegexBare for more info.
+func (u URL) ExtractArgsBare(input string) (args []any, err error) {
+	var pattern *regexp.Regexp
+	if pattern, err = u.RegexBare(); err != nil {
 		return
 	}
+	return u.extract(pattern, input)
+}
 
-	jsonBody = make(map[string]any)
-	if err = json.Unmarshal(body, &jsonBody); err != nil {
-		err = errors.Wrapf(err, "JSON could not be parsed from response from \"%s\"", req.URL.String())
+// RegexPrefix is the same as Regex, except that the regex is anchored to the start of the input, and must end at the
+// end of a path segment, query parameter, or fragment. Anything that follows the match, e.g. the rest of the path, the
+// query, or the fragment, is ignored. This sits between the loose matching of Regex, which can find a match anywhere
+// within the input, and the exact matching of RegexExact, which must match the entire input. For example,
+// "%s://store.steampowered.com/app/%d" will match the start of:
+//
+//	"https://store.steampowered.com/app/477160/Human_Fall_Flat/"
+//
+// But not "https://store.steampowered.com/app/477160abc", or "https://example.com/?u=https://store.steampowered.com/app/1".
+func (u URL) RegexPrefix() (*regexp.Regexp, error) {
+	source := `\A` + u.regexSource()
+	if format := string(u); format == "" || !strings.ContainsRune("/?#&=", rune(format[len(format)-1])) {
+		source += `(?:[/?#&]|\z)`
+	}
+	pattern, err := regexp.Compile(source)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s does not produce a valid prefix regex", u.String())
+	}
+	return pattern, nil
+}
+
+// ExtractArgsPrefix is the same as ExtractArgsErr, except that the URL format must match the start of the given URL,
+// and anything that follows the match is ignored. See RegexPrefix for more info.
+func (u URL) ExtractArgsPrefix(url string) (args []any, err error) {
+	var pattern *regexp.Regexp
+	if pattern, err = u.RegexPrefix(); err != nil {
 		return
 	}
-	return
+	return u.extract(pattern, url)
 }
 
-// RetryJSON will run JSON with the given args and try the given function. If the function returns an error then the
-// function will be retried up to a total of the given number of maxTries. If minDelay is given, and is not 0, then
-// before the function is retried it will sleep for (maxTries + 1 - currentTries) * minDelay. If a non-nil http.Request
-// is provided then it will be used to fetch the JSON resource, otherwise default http.MethodGet http.Request will be
-// constructed instead.
-func (u URL) RetryJSON(req *http.Request, maxTries int, minDelay time.Duration, try func(jsonBody map[string]any, resp *http.Response) error, args ...any) error {
-	return agem.Retry(maxTries, minDelay, func(currentTry int, maxTries int, minDelay time.Duration, args ...any) (err error) {
-		var (
-			jsonBody map[string]any
-			resp     *http.Response
-		)
-		if jsonBody, resp, err = u.JSON(req, args...); err != nil {
-			return errors.Wrapf(err, "ran out of tries (%d total) whilst requesting JSON for %s", maxTries, u.String())
-		}
-		if err = try(jsonBody, resp); err != nil {
-			return errors.Wrapf(err, "ran out of tries (%d total) whilst calling try function for %s", maxTries, u.String())
+// MaxBoundedRepeat is the maximum length that can be given to RegexBounded. This is the maximum repetition count
+// supported by the regexp package.
+const MaxBoundedRepeat = 1000
+
+// RegexBounded is the same as Regex, except that every unbounded repetition within the produced regex (e.g. the "+" in
+// the string verb pattern) is capped to match at most maxLen characters. This bounds the amount of work done by each
+// capture when matching long, adversarial inputs, and stops captures from swallowing unreasonably long strings.
+//
+// It is worth noting that the regexp package guarantees that matching runs in time linear to the size of the input,
+// so catastrophic backtracking is not possible even when using Regex. However, a format containing several adjacent
+// string verbs separated by a single literal (e.g. "%s-%s-%s") can still do a large amount of work per input byte, as
+// each verb can match the same characters.
+func (u URL) RegexBounded(maxLen int) (*regexp.Regexp, error) {
+	if maxLen < 1 || maxLen > MaxBoundedRepeat {
+		return nil, fmt.Errorf("maxLen must be between 1 and %d, not %d", MaxBoundedRepeat, maxLen)
+	}
+
+	pattern, err := u.RegexErr()
+	if err != nil {
+		return nil, err
+	}
+
+	var re *syntax.Regexp
+	if re, err = syntax.Parse(pattern.String(), syntax.Perl); err != nil {
+		return nil, errors.Wrapf(err, "could not parse regex for %s", u.String())
+	}
+	boundRepeats(re, maxLen)
+	if pattern, err = regexp.Compile(re.String()); err != nil {
+		return nil, errors.Wrapf(err, "%s does not produce a valid bounded regex", u.String())
+	}
+	return pattern, nil
+}
+
+// boundRepeats replaces each "+" and "*" operator within the given syntax.Regexp tree with a repetition that matches at
+// most maxLen times.
+func boundRepeats(re *syntax.Regexp, maxLen int) {
+	switch re.Op {
+	case syntax.OpPlus:
+		re.Op, re.Min, re.Max = syntax.OpRepeat, 1, maxLen
+	case syntax.OpStar:
+		re.Op, re.Min, re.Max = syntax.OpRepeat, 0, maxLen
+	}
+	for _, sub := range re.Sub {
+		boundRepeats(sub, maxLen)
+	}
+}
+
+// MatchTimeout is the same as Match, but will give up and return false if matching takes longer than the given
+// timeout. Matching is carried out in a separate goroutine which will run to completion even after the timeout has
+// elapsed.
+func (u URL) MatchTimeout(url string, timeout time.Duration) bool {
+	result := make(chan bool, 1)
+	go func() {
+		result <- u.Match(url)
+	}()
+
+	select {
+	case match := <-result:
+		return match
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// hostEnd returns the index of the end of the authority (host and port) within the given URL or URL format. If the
+// URL neither contains "://" nor is protocol-relative (e.g. "//store.steampowered.com") then -1 is returned.
+func hostEnd(url string) int {
+	start := len("//")
+	if !strings.HasPrefix(url, "//") {
+		if start = strings.Index(url, "://"); start < 0 {
+			return -1
 		}
-		return nil
-	}, args...)
+		start += len("://")
+	}
+	if end := strings.IndexAny(url[start:], "/?#"); end >= 0 {
+		return start + end
+	}
+	return len(url)
+}
+
+// foldHost lower-cases the scheme and host of the given URL, leaving the rest of the URL untouched.
+func foldHost(url string) string {
+	if end := hostEnd(url); end >= 0 {
+		return strings.ToLower(url[:end]) + url[end:]
+	}
+	return url
+}
+
+// foldHost returns a copy of the URL format with the literal characters of its scheme and host lower-cased. Verbs
+// within the scheme and host are left untouched.
+func (u URL) foldHost() URL {
+	format := u.String()
+	end := hostEnd(format)
+	if end < 0 {
+		return URL(format)
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range verbPattern.FindAllStringIndex(format[:end], -1) {
+		if escapedAt(format, loc[0]) {
+			continue
+		}
+		b.WriteString(strings.ToLower(format[last:loc[0]]))
+		b.WriteString(format[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(strings.ToLower(format[last:end]))
+	b.WriteString(format[end:])
+	return URL(b.String())
+}
+
+// MatchFold is the same as Match, except that the scheme and host are matched case-insensitively, as they are
+// case-insensitive according to RFC 3986. The rest of the URL, e.g. the path, is still matched case-sensitively.
+func (u URL) MatchFold(url string) bool {
+	return u.foldHost().Match(foldHost(url))
+}
+
+// ExtractArgsFold is the same as ExtractArgsErr, except that the scheme and host are matched case-insensitively (see
+// MatchFold). Any args extracted from the host will be lower-cased.
+func (u URL) ExtractArgsFold(url string) ([]any, error) {
+	return u.foldHost().ExtractArgsErr(foldHost(url))
+}
+
+// hasFragment returns whether the URL format contains a fragment.
+func (u URL) hasFragment() bool {
+	return strings.Contains(string(u), "#")
+}
+
+// candidate prepares the given URL to be matched against the URL format. If the URL format does not contain a
+// fragment, then the fragment of the given URL is stripped so that it is ignored.
+func (u URL) candidate(url string) string {
+	if !u.hasFragment() {
+		return StripFragment(url)
+	}
+	return url
+}
+
+// StripFragment removes the fragment (everything after and including the first "#") from the given URL. If the URL
+// does not contain a fragment then it is returned as is.
+func StripFragment(url string) string {
+	if i := strings.IndexByte(url, '#'); i >= 0 {
+		return url[:i]
+	}
+	return url
+}
+
+// CleanPath cleans the path of the given URL using path.Clean, which collapses repeated slashes (e.g.
+// "https://host//app//477160" becomes "https://host/app/477160"), and resolves "." and ".." elements. A trailing slash
+// is kept. The "//" after the scheme, the query, and the fragment are left untouched. If the given URL cannot be
+// parsed, or has no path, then it is returned as is.
+func CleanPath(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Opaque != "" || parsed.Path == "" {
+		return rawURL
+	}
+
+	escaped := parsed.EscapedPath()
+	cleaned := path.Clean(escaped)
+	if strings.HasSuffix(escaped, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	if parsed.Path, err = url.PathUnescape(cleaned); err != nil {
+		return rawURL
+	}
+	parsed.RawPath = cleaned
+	return parsed.String()
+}
+
+// MatchClean is the same as Match, except that the path of the given URL is cleaned using CleanPath before matching,
+// so that URLs containing accidental repeated slashes, e.g. "https://store.steampowered.com//app//477160", still match.
+func (u URL) MatchClean(url string) bool {
+	return u.Match(CleanPath(url))
+}
+
+// ExtractArgsClean is the same as ExtractArgsErr, except that the path of the given URL is cleaned using CleanPath
+// before extracting (see MatchClean).
+func (u URL) ExtractArgsClean(url string) ([]any, error) {
+	return u.ExtractArgsErr(CleanPath(url))
+}
+
+// Match the given URL with a URL to check if they are the same format. If the URL format does not contain a fragment,
+// then any fragment on the given URL is ignored.
+func (u URL) Match(url string) bool {
+	return u.Regex().MatchString(u.candidate(url))
+}
+
+// MatchErr is the same as Match, except that an error is returned if the URL format produces an invalid regex, rather
+// than panicking.
+func (u URL) MatchErr(url string) (bool, error) {
+	pattern, err := u.RegexErr()
+	if err != nil {
+		return false, err
+	}
+	return pattern.MatchString(u.candidate(url)), nil
+}
+
+// MatchScheme is the same as Match, except that the scheme of the given URL must also be one of the allowed schemes,
+// which are compared case-insensitively. This allows a URL format using the "%s://" protocol marker, which matches both
+// "http" and "https", to only accept secure URLs at the call site:
+//
+//	SteamAppPage.MatchScheme("http://store.steampowered.com/app/477160", "https") // false
+//
+// Protocol-relative URLs (e.g. "//store.steampowered.com/app/477160") have no scheme and so are never allowed. If no
+// allowed schemes are given then MatchScheme is the same as Match. False is returned if the given URL cannot be parsed.
+func (u URL) MatchScheme(rawURL string, allowed ...string) bool {
+	if !u.Match(rawURL) {
+		return false
+	}
+	if len(allowed) == 0 {
+		return true
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	for _, scheme := range allowed {
+		if parsed.Scheme != "" && strings.EqualFold(parsed.Scheme, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractArgs extracts the necessary arguments from the given URL to run the ScrapeURL.Soup, URL.JSON, and
+// URL.Fill methods. This is useful when taking a URL matched by URL.Match and fetching the soup for that
+// matched URL. If the URL format does not contain a fragment, then any fragment on the given URL is ignored.
+//
+// ExtractArgs is the inverse of Fill, so that ExtractArgs(Fill(args...)) returns the original args (as the types
+// described by VerbInfo.Kind) for the following verbs and args (see FuzzRoundTrip):
+//
+// • %d, %b, %o, %O, %x, %X, %#b, %#o, %#x, and %#X: any int64.
+//
+// • %g and %G: any float64 other than NaN and ±Inf. %e, %E, %f, and %F also round trip these floats, but to the
+// precision that they are printed with by fmt, e.g. 1.23456789 filled using %f is extracted as 1.234568.
+//
+// • %t: any bool.
+//
+// • %U: any valid rune. %c: any valid rune other than the URL delimiters "/", "?", "#", and "&".
+//
+// • %s: any non-empty string made up of the unreserved characters of RFC 3986 (letters, digits, "-", ".", "_", and
+// "~"). %#s: the same, and also the sub-delimiters, ":", and "@". %*: the same as %s, and also "/", as long as the
+// string does not contain the literal that follows the wildcard in the URL format. Fill does not escape string args,
+// so strings containing any other characters should be escaped before filling, and are extracted unescaped.
+//
+// %p does not round trip, as pointers cannot be recreated from their address.
+func (u URL) ExtractArgs(url string) (args []any) {
+	var err error
+	if args, err = u.ExtractArgsErr(url); err != nil {
+		panic(err)
+	}
+	return args
+}
+
+// ExtractArgsErr is the same as ExtractArgs, except that an error is returned instead of panicking. If the URL does
+// not match the format then the returned error will wrap ErrNoMatch. If a group could not be parsed then a *ParseError
+// will be returned.
+func (u URL) ExtractArgsErr(url string) (args []any, err error) {
+	var pattern *regexp.Regexp
+	if pattern, err = u.RegexErr(); err != nil {
+		return
+	}
+	return u.extract(pattern, url)
+}
+
+// ExtractBatch extracts the args from each of the given URLs (see ExtractArgsErr), without stopping at the first URL
+// that does not match the URL format or cannot be parsed. The returned results and errs are both the same length as
+// the given URLs, and are in the same order, so results[i] holds the args extracted from urls[i] and errs[i] holds
+// the error that occurred when extracting from urls[i]. For each URL, exactly one of these will be non-nil. The regex
+// for the URL format is only compiled once, and if it cannot be compiled then every element of errs will be that error.
+func (u URL) ExtractBatch(urls []string) (results [][]any, errs []error) {
+	results = make([][]any, len(urls))
+	errs = make([]error, len(urls))
+	pattern, err := u.RegexErr()
+	for i, url := range urls {
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		results[i], errs[i] = u.extract(pattern, url)
+	}
+	return
+}
+
+// ExtractArgsMap is the same as ExtractArgsErr, except that the extracted args are returned keyed by their position
+// within the URL format (excluding the protocol), i.e. "arg0", "arg1", and so on. See NamedURL.ExtractArgsMap to key
+// the args by name instead.
+func (u URL) ExtractArgsMap(url string) (map[string]any, error) {
+	args, err := u.ExtractArgsErr(url)
+	if err != nil {
+		return nil, err
+	}
+
+	named := make(map[string]any, len(args))
+	for i, arg := range args {
+		named[fmt.Sprintf("arg%d", i)] = arg
+	}
+	return named, nil
+}
+
+// MatchExtract matches the given URL against the URL format and extracts its args in one step, returning the args
+// keyed by their position (see ExtractArgsMap) and true if the URL matches. Unlike calling Match and then ExtractArgs,
+// the regex is only matched once, and MatchExtract never panics. If the URL does not match, or its args cannot be
+// parsed, then nil and false are returned. See NamedURL.MatchExtract to key the args by name instead.
+func (u URL) MatchExtract(url string) (map[string]any, bool) {
+	args, err := u.ExtractArgsMap(url)
+	if err != nil {
+		return nil, false
+	}
+	return args, true
+}
+
+// extract extracts the arguments from the given URL using the given pattern, which must have been produced by Regex.
+func (u URL) extract(pattern *regexp.Regexp, url string) (args []any, err error) {
+	candidate := u.candidate(url)
+	loc := pattern.FindStringSubmatchIndex(candidate)
+	if loc == nil {
+		return nil, errors.Wrapf(ErrNoMatch, "%q does not match %s", url, pattern.String())
+	}
+	return parseGroups(pattern, candidate, loc)
+}
+
+// parseGroups parses each of the groups matched by the given pattern within the given text using the parser for the
+// verb that produced the group. The given loc should be the submatch index pairs of the match (see
+// regexp.Regexp.FindStringSubmatchIndex). Groups that did not participate in the match, i.e. those within an optional
+// section (see URL.Fill) that was absent, are extracted as nil.
+func parseGroups(pattern *regexp.Regexp, text string, loc []int) (args []any, err error) {
+	groupPatterns := captureGroups(pattern.String())
+	if numGroups := len(loc)/2 - 1; numGroups != len(groupPatterns) {
+		return nil, errors.Wrapf(
+			ErrGroupCountMismatch,
+			"the number of groups matched by %s doesn't match the number of groups found in the pattern (%d vs %d)",
+			pattern.String(), numGroups, len(groupPatterns),
+		)
+	}
+	args = make([]any, len(groupPatterns))
+	for i, groupPattern := range groupPatterns {
+		start, end := loc[2*i+2], loc[2*i+3]
+		if start < 0 {
+			continue
+		}
+		group := text[start:end]
+		if parseFunc, ok := parserFor(groupPattern); ok {
+			if args[i], err = parseFunc(group); err != nil {
+				return nil, &ParseError{Value: group, Pattern: groupPattern, Err: err}
+			}
+		} else {
+			args[i] = group
+		}
+	}
+	return args, nil
+}
+
+// parserFor returns the parser for the given group pattern, if there is one.
+func parserFor(groupPattern string) (regexParserFunc, bool) {
+	if strings.HasSuffix(groupPattern, repeatedRegexSuffix) {
+		return parseRepeated, true
+	}
+	verbsMu.RLock()
+	defer verbsMu.RUnlock()
+	parseFunc, ok := regexParsers[groupPattern]
+	return parseFunc, ok
+}
+
+// captureGroups returns the source of each capturing group within the given regex source, in the order in which the
+// groups are opened. This is the same order in which regexp numbers the groups. Non-capturing groups, escaped
+// parentheses, and parentheses within character classes are skipped.
+func captureGroups(source string) []string {
+	groups := make([]string, 0)
+	// Stack of the start of each open group, paired with the group's index (or -1 if the group is non-capturing)
+	type openGroup struct{ start, index int }
+	var open []openGroup
+	inClass := false
+	for i := 0; i < len(source); i++ {
+		switch c := source[i]; {
+		case c == '\\':
+			i++
+		case inClass:
+			inClass = c != ']'
+		case c == '[':
+			inClass = true
+			// A "]" directly after the opening "[" (or "[^") is a literal
+			if strings.HasPrefix(source[i+1:], "^") {
+				i++
+			}
+			if strings.HasPrefix(source[i+1:], "]") {
+				i++
+			}
+		case c == '(':
+			rest := source[i+1:]
+			if strings.HasPrefix(rest, "?") && !strings.HasPrefix(rest, "?P<") && !strings.HasPrefix(rest, "?<") {
+				open = append(open, openGroup{i, -1})
+				continue
+			}
+			open = append(open, openGroup{i, len(groups)})
+			groups = append(groups, "")
+		case c == ')':
+			if len(open) == 0 {
+				continue
+			}
+			group := open[len(open)-1]
+			open = open[:len(open)-1]
+			if group.index >= 0 {
+				groups[group.index] = source[group.start : i+1]
+			}
+		}
+	}
+	return groups
+}
+
+// MatchAll returns all the substrings within the given text that match the URL format. This is useful for extracting
+// all the links of a known format from an HTML page or sitemap.
+func (u URL) MatchAll(text string) []string {
+	return u.Regex().FindAllString(text, -1)
+}
+
+// ExtractAllArgs extracts the arguments for each of the substrings within the given text that match the URL format
+// (see MatchAll). Each element of the returned slice is the args extracted from one match, in the order that they
+// appear in the text. Like ExtractArgs, this will panic if any of the matches cannot be parsed.
+func (u URL) ExtractAllArgs(text string) [][]any {
+	pattern := u.Regex()
+	allMatches := pattern.FindAllStringSubmatchIndex(text, -1)
+	allArgs := make([][]any, len(allMatches))
+	for i, loc := range allMatches {
+		var err error
+		if allArgs[i], err = parseGroups(pattern, text, loc); err != nil {
+			panic(err)
+		}
+	}
+	return allArgs
+}
+
+// FindArgsIndex returns the byte offsets of each of the substrings within the given text that match the URL format
+// (see MatchAll), along with the byte offsets of each of the args within those substrings. Each element of the returned
+// slice describes one match, in the order that they appear in the text, and has the following layout:
+//
+//	[matchStart, matchEnd, arg0Start, arg0End, arg1Start, arg1End, ...]
+//
+// Where the args are aligned with the verbs of the URL format (see Verbs), excluding the protocol. All offsets are
+// relative to the start of the text, so text[arg0Start:arg0End] is the substring that the first arg was extracted
+// from. If an arg did not participate in the match, then its offsets will be -1. This is useful for rewriting the args
+// of a URL in place, e.g. replacing the app ID within "/app/477160" with another app ID.
+func (u URL) FindArgsIndex(text string) [][]int {
+	return u.Regex().FindAllStringSubmatchIndex(text, -1)
+}
+
+// scanVerbSpan is the maximum number of bytes that each verb of a URL format is assumed to span when sizing the overlap
+// between the chunks read by ScanReader.
+const scanVerbSpan = 256
+
+// ScanReader returns all the substrings read from the given io.Reader that match the URL format, in the order that they
+// appear (see MatchAll). Unlike MatchAll, the reader is read in chunks using a bufio.Scanner, so that large documents
+// and HTTP response bodies, such as sitemaps, do not need to be read into memory in their entirety.
+//
+// To find matches that straddle the boundary between two chunks, the tail of each chunk is held back and prepended to
+// the next chunk. The size of this overlap is the length of the URL format plus 256 bytes for each of its verbs, so a
+// match that is longer than this may be missed or truncated if it straddles a boundary. Any error from reading the
+// given io.Reader is returned along with the matches found before the error occurred.
+func (u URL) ScanReader(r io.Reader) (matches []string, err error) {
+	var pattern *regexp.Regexp
+	if pattern, err = u.RegexErr(); err != nil {
+		return
+	}
+	overlap := len(u) + scanVerbSpan*u.NumVerbs()
+
+	matches = make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Split(scanChunks)
+	pending := ""
+	for scanner.Scan() {
+		text := pending + scanner.Text()
+		// Matches that end within the overlap could continue into the next chunk, so they are held back, along with
+		// the overlap itself
+		cut := len(text) - overlap
+		if cut < 0 {
+			cut = 0
+		}
+		last := 0
+		for _, loc := range pattern.FindAllStringIndex(text, -1) {
+			if loc[1] > cut {
+				if loc[0] < cut {
+					cut = loc[0]
+				}
+				break
+			}
+			matches = append(matches, text[loc[0]:loc[1]])
+			last = loc[1]
+		}
+		if last > cut {
+			cut = last
+		}
+		pending = text[cut:]
+	}
+	if err = scanner.Err(); err != nil {
+		err = errors.Wrapf(err, "could not scan reader for %s", u)
+		return
+	}
+	return append(matches, pattern.FindAllString(pending, -1)...), nil
+}
+
+// scanChunks is a bufio.SplitFunc that returns all the data that is currently buffered as a single token.
+func scanChunks(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if len(data) == 0 {
+		return 0, nil, nil
+	}
+	return len(data), data, nil
+}
+
+// Standardise will first extract the args from the given URL then Fill the referred to URL with those args.
+func (u URL) Standardise(url string) string {
+	args := u.ExtractArgs(url)
+	return u.Fill(args...)
+}
+
+// Normalize canonicalizes the given URL so that logically equal URLs produce byte-identical output, which is useful for
+// deduplicating scraped links. The args are extracted from the given URL (see ExtractArgsFold), and then the URL is
+// re-rendered by filling the URL format with those args. Unlike Standardise:
+//
+// • The query parameters of the given URL are matched regardless of their order.
+//
+// • The scheme of the given URL is kept, rather than being replaced with "https".
+//
+// • The scheme and host are lower-cased, and default ports (80 for "http" and 443 for "https") are removed.
+//
+// • The query parameters are sorted by key.
+//
+// An error is returned if the given URL cannot be parsed, or does not match the URL format.
+func (u URL) Normalize(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not parse %q", rawURL)
+	}
+	parsed.RawQuery = orderQuery(parsed.RawQuery, u.queryKeys())
+	stripDefaultPort(parsed)
+
+	args, err := u.ExtractArgsFold(parsed.String())
+	if err != nil {
+		return "", errors.Wrapf(err, "could not normalize %q using %s", rawURL, u.String())
+	}
+
+	normalized, err := url.Parse(u.Fill(args...))
+	if err != nil {
+		return "", errors.Wrapf(err, "could not parse %q filled from args extracted from %q", u.Fill(args...), rawURL)
+	}
+	if parsed.Scheme != "" && !u.relative() {
+		normalized.Scheme = parsed.Scheme
+	}
+	normalized.Host = strings.ToLower(normalized.Host)
+	stripDefaultPort(normalized)
+	normalized.RawQuery = orderQuery(normalized.RawQuery, nil)
+	return normalized.String(), nil
+}
+
+// stripDefaultPort removes the port from the host of the given URL if it is the default port for the URL's scheme.
+func stripDefaultPort(u *url.URL) {
+	scheme := strings.ToLower(u.Scheme)
+	if port := u.Port(); (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		u.Host = strings.TrimSuffix(u.Host, ":"+port)
+	}
+}
+
+// queryKeys returns the keys of the query parameters within the URL format, in the order in which they appear.
+func (u URL) queryKeys() []string {
+	format := u.String()
+	start := strings.IndexByte(format, '?')
+	if start < 0 {
+		return nil
+	}
+
+	query := format[start+1:]
+	if end := strings.IndexByte(query, '#'); end >= 0 {
+		query = query[:end]
+	}
+	var keys []string
+	for _, pair := range strings.Split(query, "&") {
+		key, _, _ := strings.Cut(pair, "=")
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// orderQuery reorders the parameters of the given raw query so that parameters with the given keys come first, in the
+// order of the given keys, followed by the remaining parameters sorted by key. The encoding of each parameter is left
+// untouched. Parameters with the same key keep their relative order.
+func orderQuery(rawQuery string, keys []string) string {
+	if rawQuery == "" {
+		return rawQuery
+	}
+
+	rank := make(map[string]int, len(keys))
+	for i, key := range keys {
+		if _, ok := rank[key]; !ok {
+			rank[key] = i
+		}
+	}
+
+	pairs := strings.Split(rawQuery, "&")
+	sort.SliceStable(pairs, func(i, j int) bool {
+		keyI, _, _ := strings.Cut(pairs[i], "=")
+		keyJ, _, _ := strings.Cut(pairs[j], "=")
+		rankI, okI := rank[keyI]
+		rankJ, okJ := rank[keyJ]
+		switch {
+		case okI && okJ:
+			return rankI < rankJ
+		case okI != okJ:
+			return okI
+		default:
+			return keyI < keyJ
+		}
+	})
+	return strings.Join(pairs, "&")
+}
+
+// EquivalentTo returns whether the URL format is structurally equal to the other URL format, i.e. whether both URL
+// formats produce the same regex (see Regex). Differences in how the protocol verb is given are ignored, so
+// "%s://store.steampowered.com/app/%d" and "store.steampowered.com/app/%d" are equivalent, whereas
+// "https://store.steampowered.com/app/%d" pins its scheme (see pinnedScheme) and is not. Differences in the literal
+// text, or in the type of verb in the same position (e.g. "%s" and "%d"), make the URL formats non-equivalent.
+// Synonymous verbs that produce the same pattern, such as "%f" and "%F", are equivalent. This is useful for detecting
+// accidental duplicates within a URLSet.
+func (u URL) EquivalentTo(other URL) bool {
+	return u.regexSource() == other.regexSource()
+}
+
+// Remap extracts the args from the given URL using the referred to URL format, then fills the other URL format with
+// those args. This is useful for transforming between related endpoints on the same site, such as from a Steam app's
+// store page to its reviews:
+//
+//	SteamAppPage.Remap(SteamAppReviews, "https://store.steampowered.com/app/477160")
+//
+// The verbs of the other URL format are filled positionally from the leading args extracted from the given URL, so the
+// other URL format must not have more verbs than the referred to URL format, and each of its verbs must produce the
+// same kind of value (see VerbInfo.Kind) as the verb in the same position within the referred to URL format. Any
+// trailing args that the other URL format has no verbs for are dropped. An error is returned if the given URL does not
+// match the referred to URL format, or if the two URL formats are not compatible.
+func (u URL) Remap(other URL, url string) (string, error) {
+	from, to := u.Verbs(), other.Verbs()
+	if len(to) > len(from) {
+		return "", fmt.Errorf(
+			"%s expects %d args (excluding the protocol), but only %d can be extracted using %s",
+			other.String(), len(to), len(from), u.String(),
+		)
+	}
+
+	for i, verb := range to {
+		if verb.Kind != from[i].Kind {
+			return "", fmt.Errorf(
+				"verb %d of %s (%%%s) produces a %s, but verb %d of %s (%%%s) produces a %s",
+				i, other.String(), verb.Verb, verb.Kind, i, u.String(), from[i].Verb, from[i].Kind,
+			)
+		}
+	}
+
+	args, err := u.ExtractArgsErr(url)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not remap %q from %s to %s", url, u.String(), other.String())
+	}
+	return other.Fill(args[:len(to)]...), nil
+}
+
+// MarshalText implements encoding.TextMarshaler by returning the un-formatted URL with the protocol (see String).
+func (u URL) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. The text is validated using Validate, and the protocol is
+// normalised in the same way as String. This means that a URL within a JSON or YAML
+// config file will be validated when decoded, rather than on first use.
+func (u *URL) UnmarshalText(text []byte) error {
+	decoded := URL(text)
+	if err := decoded.Validate(); err != nil {
+		return errors.Wrapf(err, "cannot unmarshal %q into URL", string(text))
+	}
+	*u = URL(decoded.String())
+	return nil
+}
+
+// GetRequest creates a new http.MethodGet http.Request for the given URL with the given arguments.
+func (u URL) GetRequest(args ...any) (url string, req *http.Request, err error) {
+	url = u.FilledURL(args...)
+	if req, err = http.NewRequest(http.MethodGet, url, nil); err != nil {
+		err = errors.Wrapf(err, "request for %q could not be created", url)
+	}
+	return
+}
+
+// GetRequestWithHeaders creates a new http.MethodGet http.Request for the given URL with the given arguments, and adds
+// each of the given headers to it.
+func (u URL) GetRequestWithHeaders(headers http.Header, args ...any) (url string, req *http.Request, err error) {
+	if url, req, err = u.GetRequest(args...); err != nil {
+		return
+	}
+	for key, values := range headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	return
+}
+
+// Request creates a new http.Request for the given URL with the given arguments, method, and io.Reader.
+func (u URL) Request(method string, body io.Reader, args ...any) (url string, req *http.Request, err error) {
+	url = u.FilledURL(args...)
+	if req, err = http.NewRequest(method, url, body); err != nil {
+		err = errors.Wrapf(err, "request for %q could not be created", url)
+	}
+	return
+}
+
+// PostJSON creates a new http.MethodPost http.Request for the given URL with the given arguments. The given payload is
+// marshalled to JSON using encoding/json and used as the body of the request, and the Content-Type header of the
+// request is set to "application/json".
+func (u URL) PostJSON(payload any, args ...any) (url string, req *http.Request, err error) {
+	var body []byte
+	if body, err = json.Marshal(payload); err != nil {
+		err = errors.Wrapf(err, "payload of type %T could not be marshalled to JSON", payload)
+		return
+	}
+
+	if url, req, err = u.Request(http.MethodPost, bytes.NewReader(body), args...); err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return
+}
+
+// RequestTemplated creates a new http.Request for the given URL filled with the given URL args, whose body is the given
+// body format filled with the given body args. This allows the body of a request to be templated in the same way as
+// its URL, e.g.
+//
+//	SteamAppReviews.RequestTemplated(http.MethodPost, `{"filter": "%s", "num_per_page": %d}`, []any{477160}, []any{"recent", 20})
+//
+// The Content-Type header of the request is inferred from the body format: if it starts with "{" or "[" then it is
+// set to "application/json", otherwise it is set to "application/x-www-form-urlencoded". Body args that are strings
+// (or fmt.Stringers) are escaped for that content type before being interpolated, i.e. they are escaped as the contents
+// of a JSON string (so the body format should surround the verb with quotes), or query escaped. All other body args
+// are interpolated as is. As with Fill, the protocol should not be included in the URL args.
+func (u URL) RequestTemplated(method string, bodyFmt string, urlArgs []any, bodyArgs []any) (url string, req *http.Request, err error) {
+	body, contentType := fillBody(bodyFmt, bodyArgs...)
+	if url, req, err = u.Request(method, strings.NewReader(body), urlArgs...); err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", contentType)
+	return
+}
+
+// fillBody fills the given body format with the given args, escaping any string args for the content type inferred
+// from the body format (see URL.RequestTemplated). The filled body is returned along with the content type.
+func fillBody(bodyFmt string, args ...any) (body string, contentType string) {
+	contentType = "application/x-www-form-urlencoded"
+	escape := url.QueryEscape
+	if trimmed := strings.TrimSpace(bodyFmt); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		contentType = "application/json"
+		escape = escapeJSONString
+	}
+
+	escaped := make([]any, len(args))
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case string:
+			escaped[i] = escape(arg)
+		case fmt.Stringer:
+			escaped[i] = escape(arg.String())
+		default:
+			escaped[i] = arg
+		}
+	}
+	return fmt.Sprintf(fmtFormat(bodyFmt), escaped...), contentType
+}
+
+// escapeJSONString escapes the given string so that it can be placed between the quotes of a JSON string.
+func escapeJSONString(s string) string {
+	// Marshalling a string never returns an error
+	quoted, _ := json.Marshal(s)
+	return string(quoted[1 : len(quoted)-1])
+}
+
+// Head makes a http.MethodHead request to the URL using the default HTTP client, returning the http.Response. This is
+// useful for checking a URL before fetching it in its entirety. As the response to a HEAD request has no body, the
+// response body is closed before Head returns. A http.Request can be provided, but if nil is provided then a default
+// http.MethodHead http.Request will be constructed instead. Unless the context of the request already has a deadline,
+// the request will time out after DefaultTimeout.
+func (u URL) Head(req *http.Request, args ...any) (resp *http.Response, err error) {
+	return u.head(http.DefaultClient, req, args...)
+}
+
+// head implements Head using the given http.Client.
+func (u URL) head(client *http.Client, req *http.Request, args ...any) (resp *http.Response, err error) {
+	if req == nil {
+		if _, req, err = u.Request(http.MethodHead, nil, args...); err != nil {
+			return
+		}
+	}
+
+	req, cancel := withDefaultTimeout(req)
+	defer cancel()
+
+	start := time.Now()
+	defer func() { observe(req.URL.String(), resp, start, err) }()
+
+	if resp, err = client.Do(req); err != nil {
+		err = fetchError(err, req.URL.String(), 0, "could not make HEAD request to %s", req.URL.String())
+		return
+	}
+
+	if resp.Body != nil {
+		err = errors.Wrapf(resp.Body.Close(), "could not close response body to %s", req.URL.String())
+	}
+	return
+}
+
+// Exists checks whether the URL filled with the given args exists by making a HEAD request to it (see Head). A 2xx or
+// 3xx status is treated as existing, any other status is treated as not existing. Some servers do not support HEAD
+// requests and will respond with 405 Method Not Allowed or 501 Not Implemented. As these are inconclusive, Exists will
+// fall back to making a ranged GET request for the first byte of the resource, and use the status of that instead.
+// Each request will time out after DefaultTimeout (see ExistsWithClient).
+func (u URL) Exists(args ...any) (exists bool, err error) {
+	return u.ExistsWithClient(http.DefaultClient, nil, args...)
+}
+
+// ExistsWithClient is the same as Exists, except that the given http.Client is used to make the requests, so that a
+// client created using NewClient, or one whose CheckRedirect returns http.ErrUseLastResponse (as with WithoutRedirects)
+// so that a redirect is treated as existing rather than followed, can be used to check whether a URL exists. If the
+// given client is nil then the default HTTP client is used. If a non-nil http.Request is provided then its URL,
+// headers, and context are used for both the HEAD request and the ranged GET request, but its method is replaced.
+// Unless the context of the request already has a deadline, each request will time out after DefaultTimeout.
+func (u URL) ExistsWithClient(client *http.Client, req *http.Request, args ...any) (exists bool, err error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var head *http.Request
+	if req != nil {
+		head = req.Clone(req.Context())
+		head.Method = http.MethodHead
+	}
+
+	var resp *http.Response
+	if resp, err = u.head(client, head, args...); err != nil {
+		return
+	}
+
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		if resp, err = u.rangedGet(client, req, args...); err != nil {
+			return
+		}
+	}
+	return resp.StatusCode >= 200 && resp.StatusCode < 400, nil
+}
+
+// rangedGet makes a http.MethodGet request for the first byte of the URL filled with the given args, for when a HEAD
+// request is inconclusive (see ExistsWithClient). If a non-nil http.Request is provided then a clone of it is used
+// instead. The response body is closed before rangedGet returns.
+func (u URL) rangedGet(client *http.Client, req *http.Request, args ...any) (resp *http.Response, err error) {
+	if req == nil {
+		if _, req, err = u.GetRequest(args...); err != nil {
+			return
+		}
+	} else {
+		req = req.Clone(req.Context())
+		req.Method = http.MethodGet
+	}
+	req.Header.Set("Range", "bytes=0-0")
+
+	req, cancel := withDefaultTimeout(req)
+	defer cancel()
+
+	start := time.Now()
+	defer func() { observe(req.URL.String(), resp, start, err) }()
+
+	if resp, err = client.Do(req); err != nil {
+		err = fetchError(err, req.URL.String(), 0, "could not make ranged GET request to %s", req.URL.String())
+		return
+	}
+	if resp.Body != nil {
+		err = errors.Wrapf(resp.Body.Close(), "could not close response body to %s", req.URL.String())
+	}
+	return
+}
+
+// fetchMessages are the formats of the messages that wrap the errors returned by fetch, so that each caller can
+// describe what it was fetching. Each format is given the URL of the request.
+type fetchMessages struct {
+	// get wraps the error returned when the request could not be made.
+	get string
+	// read wraps the error returned when the response body could not be read.
+	read string
+	// close wraps the error returned when the response body could not be closed.
+	close string
+}
+
+var (
+	// pageFetchMessages are the fetchMessages used when fetching a page, e.g. by Soup.
+	pageFetchMessages = fetchMessages{
+		get:   "could not get page %s",
+		read:  "could not read response body to %s",
+		close: "could not close response body to %s",
+	}
+	// jsonFetchMessages are the fetchMessages used when fetching a JSON resource, e.g. by JSON and JSONInto.
+	jsonFetchMessages = fetchMessages{
+		get:   "JSON could not be fetched from \"%s\"",
+		read:  "JSON request body from \"%s\" could not be read",
+		close: "request body for JSON fetched from \"%s\" could not be closed",
+	}
+)
+
+// fetch fetches the URL using the default HTTP client and reads the entire response body, which is closed before fetch
+// returns. Any error that occurs whilst closing the response body is merged with the returned error. This is shared by
+// all the methods that fetch and parse an entire page, such as Soup and JSON, which each give the messages that wrap
+// the errors that can occur. A http.Request can be provided, but if nil is provided then a default http.MethodGet
+// http.Request will be constructed instead. Unless the context of the request already has a deadline, the request will
+// time out after DefaultTimeout.
+func (u URL) fetch(messages fetchMessages, req *http.Request, args ...any) (body []byte, resp *http.Response, err error) {
+	return u.fetchWith(http.DefaultClient, messages, req, args...)
+}
+
+// fetchWith is the same as fetch, except that the given http.Client is used to make the request. The client is used as
+// is, so that any http.CookieJar, http.RoundTripper, or redirect policy set on it is respected. If the given client is
+// nil then the default HTTP client is used.
+func (u URL) fetchWith(client *http.Client, messages fetchMessages, req *http.Request, args ...any) (body []byte, resp *http.Response, err error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if req == nil {
+		if _, req, err = u.GetRequest(args...); err != nil {
+			return
+		}
+	}
+
+	req, cancel := withDefaultTimeout(req)
+	defer cancel()
+
+	start := time.Now()
+	defer func() { observe(req.URL.String(), resp, start, err) }()
+
+	if resp, err = client.Do(req); err != nil {
+		err = fetchError(err, req.URL.String(), 0, messages.get, req.URL.String())
+		return
+	}
+	if resp.Request == nil {
+		// Custom RoundTrippers are not required to set the Request of the response
+		resp.Request = req
+	}
+
+	if resp.Body != nil {
+		defer func(body io.ReadCloser) {
+			err = agem.MergeErrors(err, errors.Wrapf(body.Close(), messages.close, req.URL.String()))
+		}(resp.Body)
+	}
+
+	var reader io.ReadCloser
+	if reader, err = decompress(resp); err != nil {
+		err = fetchError(err, req.URL.String(), resp.StatusCode, "could not decompress response body to %s", req.URL.String())
+		return
+	}
+	if reader != resp.Body {
+		defer func(reader io.ReadCloser) {
+			err = agem.MergeErrors(err, errors.Wrapf(reader.Close(), "could not close decompressor for response body to %s", req.URL.String()))
+		}(reader)
+	}
+
+	limit := maxBytes(req.Context())
+	if body, err = io.ReadAll(io.LimitReader(reader, limit+1)); err != nil {
+		err = fetchError(err, req.URL.String(), resp.StatusCode, messages.read, req.URL.String())
+		return
+	}
+	if int64(len(body)) > limit {
+		body = nil
+		err = fetchError(ErrBodyTooLarge, req.URL.String(), resp.StatusCode, "response body to %s exceeds %d bytes", req.URL.String(), limit)
+		return
+	}
+	return
+}
+
+// decompress wraps the body of the given response in a reader that decompresses it according to the response's
+// Content-Encoding header. The http.Transport only decompresses a response transparently when it added the
+// "Accept-Encoding: gzip" header to the request itself, so this handles servers that compress their responses
+// unconditionally, or requests that set the Accept-Encoding header explicitly. Both "gzip" and "deflate" are supported.
+// As some servers send raw DEFLATE data for "deflate", rather than the zlib format required by RFC 9110, zlib is only
+// used when the body begins with a valid zlib header. If the body is not compressed then it is returned as is. The
+// returned reader does not close the response body when closed.
+func decompress(resp *http.Response) (io.ReadCloser, error) {
+	if resp.Uncompressed {
+		return resp.Body, nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		return gzip.NewReader(resp.Body)
+	case "deflate":
+		buffered := bufio.NewReader(resp.Body)
+		if header, err := buffered.Peek(2); err == nil && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(buffered)
+		}
+		return flate.NewReader(buffered), nil
+	default:
+		return resp.Body, nil
+	}
+}
+
+// decompressedBody is a response body that reads from the decompressor for the original response body (see
+// decompress), and closes both when closed. This allows a decompressed body to be handed to a caller that only knows to
+// close http.Response.Body.
+type decompressedBody struct {
+	io.ReadCloser
+	body io.ReadCloser
+}
+
+func (b *decompressedBody) Close() error {
+	return agem.MergeErrors(b.ReadCloser.Close(), b.body.Close())
+}
+
+// Soup fetches the URL using the default HTTP client, then parses the returned HTML page into a soup.Root. It
+// also returns the http.Response object returned by the http.Get request. A http.Request can be provided, but if nil is
+// provided then a default http.MethodGet http.Request will be constructed instead. Unless the context of the request
+// already has a deadline, the request will time out after DefaultTimeout (see SoupTimeout).
+func (u URL) Soup(req *http.Request, args ...any) (doc *soup.Root, resp *http.Response, err error) {
+	return u.SoupWithClient(http.DefaultClient, req, args...)
+}
+
+// SoupWithClient is the same as Soup, except that the given http.Client is used to make the request. The client is used
+// as is, so a client with a http.CookieJar can be given to persist cookies across requests, e.g. when scraping pages
+// that require a login (see Session). If the given client is nil then the default HTTP client is used.
+func (u URL) SoupWithClient(client *http.Client, req *http.Request, args ...any) (doc *soup.Root, resp *http.Response, err error) {
+	var body []byte
+	if body, resp, err = u.fetchWith(client, pageFetchMessages, req, args...); err != nil {
+		return
+	}
+	doc = ParseSoupBytes(body)
+	return
+}
+
+// ParseSoup reads the given io.Reader in its entirety and parses it as HTML, in the same way that Soup parses the body
+// of a response. This allows HTML that has already been fetched, or that is read from a file, to be searched without
+// making a request. An error is returned if the io.Reader could not be read.
+func ParseSoup(r io.Reader) (*soup.Root, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read HTML")
+	}
+	return ParseSoupBytes(body), nil
+}
+
+// ParseSoupBytes is the same as ParseSoup, except that the HTML is given as a []byte.
+func ParseSoupBytes(body []byte) *soup.Root {
+	root := soup.HTMLParse(string(body))
+	return &root
+}
+
+// Resolve fetches the URL filled with the given args, following any redirects, and returns the URL that it finally
+// landed on (see FinalURL). This is useful for canonicalising scraped links that go through redirectors or URL
+// shorteners. The response body is read and closed before Resolve returns.
+func (u URL) Resolve(args ...any) (finalURL string, resp *http.Response, err error) {
+	return u.ResolveWithClient(http.DefaultClient, nil, args...)
+}
+
+// ResolveWithClient is the same as Resolve, except that the given http.Client is used to make the request (see
+// SoupWithClient). If a non-nil http.Request is given then it is used as is, so that its context and headers are kept.
+func (u URL) ResolveWithClient(client *http.Client, req *http.Request, args ...any) (finalURL string, resp *http.Response, err error) {
+	if _, resp, err = u.fetchWith(client, pageFetchMessages, req, args...); err != nil {
+		return
+	}
+	return FinalURL(resp), resp, nil
+}
+
+// requestFrom extracts the args from the given raw URL, then creates a request for the URL filled with those args. If
+// a non-nil http.Request is provided then it is cloned, and the clone's URL is replaced with the filled URL, so that
+// the method, headers, and context of the given request are kept. If the raw URL does not match the URL format then
+// the returned error will wrap ErrNoMatch.
+func (u URL) requestFrom(rawURL string, req *http.Request) (*http.Request, error) {
+	args, err := u.ExtractArgsErr(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if req == nil {
+		_, req, err = u.GetRequest(args...)
+		return req, err
+	}
+
+	var filled *http.Request
+	if _, filled, err = u.GetRequest(args...); err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.URL, req.Host = filled.URL, filled.Host
+	return req, nil
+}
+
+// SoupFrom extracts the args from the given raw URL (see ExtractArgsErr), then fetches the URL filled with those args
+// using Soup. This is useful when you have a concrete URL that is known to match the URL format and want to scrape it
+// in one call. If a non-nil http.Request is provided then its method, headers, and context will be used, but its URL
+// will be replaced. If the raw URL does not match the URL format then the returned error will wrap ErrNoMatch.
+func (u URL) SoupFrom(rawURL string, req *http.Request) (doc *soup.Root, resp *http.Response, err error) {
+	if req, err = u.requestFrom(rawURL, req); err != nil {
+		return
+	}
+	return u.Soup(req)
+}
+
+// SoupWithHeaders is the same as Soup, except that when req is nil, the default http.MethodGet http.Request that is
+// constructed will have the given headers added to it. This is useful for setting an Authorization or User-Agent
+// header. The headers are never applied to a caller-supplied http.Request.
+func (u URL) SoupWithHeaders(req *http.Request, headers http.Header, args ...any) (doc *soup.Root, resp *http.Response, err error) {
+	if req != nil {
+		return u.Soup(req, args...)
+	}
+	return u.SoupOpts(args, WithHeaders(headers))
+}
+
+// DefaultTimeout is the timeout applied to the requests made by Soup, JSON, JSONDecode, and JSONInto, unless the
+// context of the request already has a deadline. The timeout covers the entire request, including reading the
+// response body.
+const DefaultTimeout = time.Second * 10
+
+// DefaultMaxBytes is the maximum number of bytes of a response body that are read by Soup, JSON, and the functions and
+// methods built on top of them, unless a different maximum is given using WithMaxBytes or ContextWithMaxBytes. This
+// protects long-running scrapers from running out of memory when an endpoint returns a huge response. The maximum
+// applies to the decompressed body.
+const DefaultMaxBytes int64 = 32 << 20
+
+// maxBytesKey is the context key for the maximum number of bytes of a response body that are read (see
+// ContextWithMaxBytes).
+type maxBytesKey struct{}
+
+// ContextWithMaxBytes returns a copy of the given context that makes requests that use it read at most n bytes of the
+// response body, instead of DefaultMaxBytes. A request that is given to Soup, JSON, and the like can be made with this
+// context to override the maximum for that request. If n is not positive then DefaultMaxBytes is used.
+func ContextWithMaxBytes(ctx context.Context, n int64) context.Context {
+	return context.WithValue(ctx, maxBytesKey{}, n)
+}
+
+// maxBytes returns the maximum number of bytes of a response body that are read for requests using the given context.
+func maxBytes(ctx context.Context) int64 {
+	if n, ok := ctx.Value(maxBytesKey{}).(int64); ok && n > 0 {
+		return n
+	}
+	return DefaultMaxBytes
+}
+
+// withDefaultTimeout returns a shallow copy of the given request with a context that times out after DefaultTimeout,
+// unless the context of the request already has a deadline. The returned context.CancelFunc should be called once the
+// response body has been read.
+func withDefaultTimeout(req *http.Request) (*http.Request, context.CancelFunc) {
+	if _, ok := req.Context().Deadline(); ok {
+		return req, func() {}
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), DefaultTimeout)
+	return req.WithContext(ctx), cancel
+}
+
+// SoupTimeout is the same as Soup, except that the default http.MethodGet http.Request that is constructed will time
+// out after the given duration instead of DefaultTimeout. The timeout is implemented using context.WithTimeout, so it
+// cancels the request whilst the response body is being read, as well as whilst connecting.
+func (u URL) SoupTimeout(d time.Duration, args ...any) (doc *soup.Root, resp *http.Response, err error) {
+	return u.SoupOpts(args, WithTimeout(d))
+}
+
+// RetrySoup will run Soup with the given args and try the given function. If the function returns an error then the
+// function will be retried up to a total of the given number of maxTries. If minDelay is given, and is not 0, then
+// before the function is retried it will sleep for (maxTries + 1 - currentTries) * minDelay. If a non-nil http.Request
+// is provided then it will be used to fetch the page for the Soup, otherwise a default http.MethodGet http.Request will
+// be constructed instead. Use RetrySoupWith to configure the backoff strategy.
+func (u URL) RetrySoup(req *http.Request, maxTries int, minDelay time.Duration, try func(doc *soup.Root, resp *http.Response) error, args ...any) error {
+	return u.RetrySoupWith(req, RetryConfig{MaxTries: maxTries, MinDelay: minDelay, Backoff: LinearBackoff}, try, args...)
+}
+
+// JSON makes a request to the URL and parses the response to JSON. As well as returning the parsed JSON as a map,
+// it also returns the response to the original HTTP request made to the given URL. If a non-nil http.Request is
+// provided then it will be used to fetch the JSON resource, otherwise default http.MethodGet http.Request will be
+// constructed instead. Unless the context of the request already has a deadline, the request will time out after
+// DefaultTimeout (see JSONTimeout).
+func (u URL) JSON(req *http.Request, args ...any) (jsonBody map[string]any, resp *http.Response, err error) {
+	return u.JSONWithClient(http.DefaultClient, req, args...)
+}
+
+// JSONWithClient is the same as JSON, except that the given http.Client is used to make the request. The client is used
+// as is, so a client with a http.CookieJar can be given to persist cookies across requests (see Session). If the given
+// client is nil then the default HTTP client is used.
+func (u URL) JSONWithClient(client *http.Client, req *http.Request, args ...any) (jsonBody map[string]any, resp *http.Response, err error) {
+	_, jsonBody, resp, err = u.jsonRaw(client, req, args...)
+	return
+}
+
+// JSONRaw is the same as JSON, except that the raw bytes of the response body are also returned alongside the parsed
+// JSON. This is useful for hashing, storing, or re-parsing the response into a struct without making a second request.
+// The returned body is read into a new slice for each call, and so is owned by the caller. The raw bytes are returned
+// even if the body could not be parsed as JSON.
+func (u URL) JSONRaw(req *http.Request, args ...any) (body []byte, jsonBody map[string]any, resp *http.Response, err error) {
+	return u.jsonRaw(http.DefaultClient, req, args...)
+}
+
+// jsonRaw fetches the URL using the given client, then parses the response body as JSON. Both the raw bytes of the
+// response body and the parsed JSON are returned.
+func (u URL) jsonRaw(client *http.Client, req *http.Request, args ...any) (body []byte, jsonBody map[string]any, resp *http.Response, err error) {
+	if body, resp, err = u.fetchWith(client, jsonFetchMessages, req, args...); err != nil {
+		return
+	}
+
+	jsonBody = make(map[string]any)
+	if err = json.Unmarshal(body, &jsonBody); err != nil {
+		err = &ParseError{
+			Value: string(body),
+			Err:   errors.Wrapf(err, "JSON could not be parsed from response from \"%s\"", resp.Request.URL.String()),
+		}
+		return
+	}
+	return
+}
+
+// JSONFrom extracts the args from the given raw URL (see ExtractArgsErr), then fetches the URL filled with those args
+// using JSON. If a non-nil http.Request is provided then its method, headers, and context will be used, but its URL
+// will be replaced. If the raw URL does not match the URL format then the returned error will wrap ErrNoMatch.
+func (u URL) JSONFrom(rawURL string, req *http.Request) (jsonBody map[string]any, resp *http.Response, err error) {
+	if req, err = u.requestFrom(rawURL, req); err != nil {
+		return
+	}
+	return u.JSON(req)
+}
+
+// JSONStream makes a request to the URL and returns a json.Decoder that reads directly from the response body, rather
+// than buffering the entire body into memory like JSON. This allows very large responses to be decoded incrementally,
+// e.g. token-by-token using json.Decoder.Token. The caller takes ownership of the response body and must close it once
+// they have finished decoding. As the body is read by the caller, the default HTTP client is used, which has no
+// timeout. A context can be attached to a caller-supplied http.Request to bound the request. If a non-nil
+// http.Request is provided then it will be used to fetch the JSON resource, otherwise default http.MethodGet
+// http.Request will be constructed instead. A compressed response body is decompressed (see decompress), in which case
+// the response body is replaced with one that closes both the decompressor and the original body.
+func (u URL) JSONStream(req *http.Request, args ...any) (decoder *json.Decoder, resp *http.Response, err error) {
+	if req == nil {
+		if _, req, err = u.GetRequest(args...); err != nil {
+			return
+		}
+	}
+
+	start := time.Now()
+	defer func() { observe(req.URL.String(), resp, start, err) }()
+
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		err = fetchError(err, req.URL.String(), 0, "JSON could not be fetched from \"%s\"", req.URL.String())
+		return
+	}
+
+	var reader io.ReadCloser
+	if reader, err = decompress(resp); err != nil {
+		err = agem.MergeErrors(
+			fetchError(err, req.URL.String(), resp.StatusCode, "could not decompress response body to %s", req.URL.String()),
+			errors.Wrapf(resp.Body.Close(), "request body for JSON fetched from \"%s\" could not be closed", req.URL.String()),
+		)
+		return
+	}
+	if reader != resp.Body {
+		resp.Body = &decompressedBody{ReadCloser: reader, body: resp.Body}
+	}
+	return json.NewDecoder(resp.Body), resp, nil
+}
+
+// JSONDecode is the same as JSON, except that the response body is decoded as it is read using a json.Decoder, rather
+// than being read into memory in its entirety before being parsed. A compressed response body is decompressed (see
+// decompress). As with JSON, at most DefaultMaxBytes of the response body are read, unless a different maximum is given
+// using ContextWithMaxBytes, and a JSON value that does not end within the maximum results in an error wrapping
+// ErrBodyTooLarge. The response body is closed before JSONDecode returns.
+func (u URL) JSONDecode(req *http.Request, args ...any) (jsonBody map[string]any, resp *http.Response, err error) {
+	if req == nil {
+		if _, req, err = u.GetRequest(args...); err != nil {
+			return
+		}
+	}
+
+	req, cancel := withDefaultTimeout(req)
+	defer cancel()
+
+	start := time.Now()
+	defer func() { observe(req.URL.String(), resp, start, err) }()
+
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		err = fetchError(err, req.URL.String(), 0, "JSON could not be fetched from \"%s\"", req.URL.String())
+		return
+	}
+
+	var reader io.ReadCloser
+	if resp.Body != nil {
+		defer func(Body io.ReadCloser) {
+			if reader != nil && reader != Body {
+				err = agem.MergeErrors(err, errors.Wrapf(reader.Close(), "could not close decompressor for response body to %s", req.URL.String()))
+			}
+			err = agem.MergeErrors(err, errors.Wrapf(
+				Body.Close(),
+				"request body for JSON fetched from \"%s\" could not be closed",
+				req.URL.String(),
+			))
+		}(resp.Body)
+	}
+
+	if reader, err = decompress(resp); err != nil {
+		err = fetchError(err, req.URL.String(), resp.StatusCode, "could not decompress response body to %s", req.URL.String())
+		return
+	}
+
+	limit := maxBytes(req.Context())
+	limited := &io.LimitedReader{R: reader, N: limit + 1}
+	jsonBody = make(map[string]any)
+	if err = json.NewDecoder(limited).Decode(&jsonBody); err != nil {
+		if limited.N <= 0 {
+			// The decoder ran out of input because the body was truncated at the maximum
+			jsonBody = nil
+			err = fetchError(ErrBodyTooLarge, req.URL.String(), resp.StatusCode, "response body to %s exceeds %d bytes", req.URL.String(), limit)
+			return
+		}
+		err = &ParseError{Err: errors.Wrapf(err, "JSON could not be decoded from response from \"%s\"", req.URL.String())}
+		return
+	}
+	return
+}
+
+// JSONWithHeaders is the same as JSON, except that when req is nil, the default http.MethodGet http.Request that is
+// constructed will have the given headers added to it. This is useful for setting an Authorization or Accept header.
+// The headers are never applied to a caller-supplied http.Request.
+func (u URL) JSONWithHeaders(req *http.Request, headers http.Header, args ...any) (jsonBody map[string]any, resp *http.Response, err error) {
+	if req != nil {
+		return u.JSON(req, args...)
+	}
+	return u.JSONOpts(args, WithHeaders(headers))
+}
+
+// JSONTimeout is the same as JSON, except that the default http.MethodGet http.Request that is constructed will time
+// out after the given duration instead of DefaultTimeout. The timeout is implemented using context.WithTimeout, so it
+// cancels the request whilst the response body is being read, as well as whilst connecting.
+func (u URL) JSONTimeout(d time.Duration, args ...any) (jsonBody map[string]any, resp *http.Response, err error) {
+	return u.JSONOpts(args, WithTimeout(d))
+}
+
+// RetryJSON will run JSON with the given args and try the given function. If the function returns an error then the
+// function will be retried up to a total of the given number of maxTries. If minDelay is given, and is not 0, then
+// before the function is retried it will sleep for (maxTries + 1 - currentTries) * minDelay. If a non-nil http.Request
+// is provided then it will be used to fetch the JSON resource, otherwise default http.MethodGet http.Request will be
+// constructed instead. Use RetryJSONWith to configure the backoff strategy.
+func (u URL) RetryJSON(req *http.Request, maxTries int, minDelay time.Duration, try func(jsonBody map[string]any, resp *http.Response) error, args ...any) error {
+	return u.RetryJSONWith(req, RetryConfig{MaxTries: maxTries, MinDelay: minDelay, Backoff: LinearBackoff}, try, args...)
+}
+
+// JSONInto makes a request to the given URL and unmarshals the response body into a value of type T. As well as
+// returning the decoded value, it also returns the response to the original HTTP request made to the given URL. If a
+// non-nil http.Request is provided then it will be used to fetch the JSON resource, otherwise default http.MethodGet
+// http.Request will be constructed instead. This is a type-safe alternative to URL.JSON, which always decodes into a
+// map[string]any.
+func JSONInto[T any](u URL, req *http.Request, args ...any) (jsonBody T, resp *http.Response, err error) {
+	var body []byte
+	if body, resp, err = u.fetch(jsonFetchMessages, req, args...); err != nil {
+		return
+	}
+
+	if err = json.Unmarshal(body, &jsonBody); err != nil {
+		err = &ParseError{
+			Value: string(body),
+			Err:   errors.Wrapf(err, "JSON could not be parsed from response from \"%s\"", resp.Request.URL.String()),
+		}
+		return
+	}
+	return
+}
+
+// PostJSONInto makes a http.MethodPost request to the given URL with the given payload marshalled to JSON as its body
+// (see URL.PostJSON), then unmarshals the response body into a value of type T (see JSONInto).
+func PostJSONInto[T any](u URL, payload any, args ...any) (jsonBody T, resp *http.Response, err error) {
+	var req *http.Request
+	if _, req, err = u.PostJSON(payload, args...); err != nil {
+		return
+	}
+	return JSONInto[T](u, req)
 }
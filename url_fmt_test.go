@@ -1,8 +1,29 @@
 package urlfmt
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
 	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+	"unicode"
+	"unicode/utf8"
+	"unsafe"
 )
 
 func ExampleURL_Regex() {
@@ -14,8 +35,382 @@ func ExampleURL_Regex() {
 	fmt.Println(SteamAppPage.Regex())
 	fmt.Println(ItchIOGamePage.Regex())
 	// Output:
-	// https?://store.steampowered.com/app/(\d+)
-	// https?://([a-zA-Z0-9-._~]+).itch.io/([a-zA-Z0-9-._~]+)
+	// (?:https?:)?//store\.steampowered\.com/app/([+-]?\d+)
+	// (?:https?:)?//((?:[a-zA-Z0-9-._~]|%[0-9A-Fa-f]{2})+)\.itch\.io/((?:[a-zA-Z0-9-._~]|%[0-9A-Fa-f]{2})+)
+}
+
+func ExampleURL_Fill() {
+	const (
+		SteamAppReviews   URL = "%s://store.steampowered.com/app/%d/reviews"
+		RelativeAppReview URL = "/app/%d/reviews"
+	)
+
+	fmt.Println(SteamAppReviews.Fill(477160))
+	fmt.Println(RelativeAppReview.Fill(477160))
+	fmt.Println(RelativeAppReview.Regex())
+	fmt.Println(RelativeAppReview.ExtractArgs("https://store.steampowered.com/app/477160/reviews"))
+	// Output:
+	// https://store.steampowered.com/app/477160/reviews
+	// /app/477160/reviews
+	// /app/([+-]?\d+)/reviews
+	// [477160]
+}
+
+func TestURL_FilledURL(t *testing.T) {
+	const SteamAppReviews URL = "%s://store.steampowered.com/app/%d/reviews?filter=%s"
+	filled := SteamAppReviews.FilledURL(477160, "recent")
+	url, req, err := SteamAppReviews.GetRequest(477160, "recent")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filled != url || filled != req.URL.String() {
+		t.Errorf("expected FilledURL to return %q and %q, got %q", url, req.URL.String(), filled)
+	}
+
+	if actual := SteamAppReviews.MustFill(477160, "recent"); actual != filled {
+		t.Errorf("expected MustFill to return %q, got %q", filled, actual)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected MustFill to panic when given too few args")
+		}
+	}()
+	SteamAppReviews.MustFill(477160)
+}
+
+func TestURL_FillURL(t *testing.T) {
+	const SteamAppReviews URL = "%s://store.steampowered.com/app/%d/reviews?filter=%s"
+	u, err := SteamAppReviews.FillURL(477160, "recent")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u.Scheme != "https" || u.Host != "store.steampowered.com" || u.Path != "/app/477160/reviews" || u.Query().Get("filter") != "recent" {
+		t.Errorf("unexpected URL %#v", u)
+	}
+
+	if _, err = SteamAppReviews.FillURL(477160, "recent\x7f"); err == nil {
+		t.Errorf("expected an error for a URL containing a control character")
+	}
+}
+
+func TestURL_FillCanonical(t *testing.T) {
+	const (
+		SteamAppReviews          URL = "%s://store.steampowered.com/appreviews/%d?json=1&filter=%s&cursor=%s#%s"
+		SteamAppReviewsReordered URL = "%s://store.steampowered.com/appreviews/%d?cursor=%s&json=1&filter=%s#%s"
+	)
+	expected := "https://store.steampowered.com/appreviews/477160?cursor=AoJ4&filter=recent+first&json=1#Top%20Reviews"
+	for _, test := range []struct {
+		u    URL
+		args []any
+	}{
+		{SteamAppReviews, []any{477160, "recent+first", "AoJ4", "Top%20Reviews"}},
+		{SteamAppReviewsReordered, []any{477160, "AoJ4", "recent+first", "Top%20Reviews"}},
+	} {
+		actual, err := test.u.FillCanonical(test.args...)
+		if err != nil {
+			t.Errorf("unexpected error for %s: %v", test.u, err)
+		} else if actual != expected {
+			t.Errorf("expected %s to be filled as %q, got %q", test.u, expected, actual)
+		}
+	}
+
+	if _, err := SteamAppReviews.FillCanonical(477160, "recent", "AoJ4%", "top"); err == nil {
+		t.Errorf("expected an error for a query containing an invalid escape")
+	}
+}
+
+func TestURL_GroupInfo(t *testing.T) {
+	for _, test := range []struct {
+		u             URL
+		verbCount     int
+		groupPatterns []string
+	}{
+		{
+			"%s://store.steampowered.com/app/%d",
+			1,
+			[]string{string(base10VerbRegexPattern)},
+		},
+		{
+			"%s://%s.itch.io/%s",
+			2,
+			[]string{string(stringVerbRegexPattern), string(stringVerbRegexPattern)},
+		},
+	} {
+		verbCount, groupPatterns := test.u.GroupInfo()
+		if verbCount != test.verbCount {
+			t.Errorf("expected %s to have %d verbs, got %d", test.u, test.verbCount, verbCount)
+		}
+		if !reflect.DeepEqual(groupPatterns, test.groupPatterns) {
+			t.Errorf("expected %s to have the group patterns %v, got %v", test.u, test.groupPatterns, groupPatterns)
+		}
+		if len(groupPatterns) != verbCount {
+			t.Errorf("expected %s to have as many groups as verbs", test.u)
+		}
+	}
+}
+
+func TestURL_Signature(t *testing.T) {
+	for _, test := range []struct {
+		u         URL
+		signature string
+		argTypes  []reflect.Kind
+	}{
+		{"%s://store.steampowered.com/app/%d", "{scheme}://store.steampowered.com/app/{d}", []reflect.Kind{reflect.Int64}},
+		{"%s://%s.itch.io/%s", "{scheme}://{s}.itch.io/{s}", []reflect.Kind{reflect.String, reflect.String}},
+		{"store.steampowered.com/app/%d", "{scheme}://store.steampowered.com/app/{d}", []reflect.Kind{reflect.Int64}},
+		{"/app/%d/reviews?filter=%s", "/app/{d}/reviews?filter={s}", []reflect.Kind{reflect.Int64, reflect.String}},
+		{"steam://run/%d", "steam://run/{d}", []reflect.Kind{reflect.Int64}},
+		{
+			"%s://localhost%{:%d%}/search?{tags...}&discount=%d%%",
+			"{scheme}://localhost[:{d}]/search?{tags...}&discount={d}%",
+			[]reflect.Kind{reflect.Int64, reflect.Slice, reflect.Int64},
+		},
+	} {
+		t.Run(string(test.u), func(t *testing.T) {
+			if actual := test.u.Signature(); actual != test.signature {
+				t.Errorf("expected signature %q, got %q", test.signature, actual)
+			}
+			if actual := test.u.ArgTypes(); !reflect.DeepEqual(actual, test.argTypes) {
+				t.Errorf("expected arg types %v, got %v", test.argTypes, actual)
+			}
+		})
+	}
+}
+
+func TestURL_escapedPercent(t *testing.T) {
+	const AmountPage URL = "%s://example.com/amount/%%d/%d?discount=%d%%%%20"
+	if err := AmountPage.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if actual := AmountPage.NumVerbs(); actual != 2 {
+		t.Errorf("expected 2 verbs, got %d", actual)
+	}
+	if verbs := AmountPage.Verbs(); len(verbs) != 2 || verbs[0].Offset != strings.Index(AmountPage.String(), "%%d/")+len("%%d/") {
+		t.Errorf("expected the escaped %%%%d not to be treated as a verb, got %+v", verbs)
+	}
+
+	expected := `(?:https?:)?//example\.com/amount/%d/([+-]?\d+)\?discount=([+-]?\d+)%%20`
+	if actual := AmountPage.Regex().String(); actual != expected {
+		t.Errorf("expected regex %q, got %q", expected, actual)
+	}
+
+	filled := AmountPage.Fill(5, 10)
+	if filled != "https://example.com/amount/%d/5?discount=10%%20" {
+		t.Errorf("unexpected filled URL %q", filled)
+	}
+	if args := AmountPage.ExtractArgs(filled); !reflect.DeepEqual(args, []any{int64(5), int64(10)}) {
+		t.Errorf("expected [5 10], got %v", args)
+	}
+	if actual := AmountPage.Partial(5).Fill(10); actual != filled {
+		t.Errorf("expected partially applied URL to be filled as %q, got %q", filled, actual)
+	}
+}
+
+func TestURL_WithBase(t *testing.T) {
+	const AppPage URL = "/app/%d"
+	for _, test := range []struct {
+		base     string
+		expected URL
+		url      string
+	}{
+		{"https://store.steampowered.com", "https://store.steampowered.com/app/%d", "https://store.steampowered.com/app/477160"},
+		{"http://store.steampowered.com/search?term=portal#results", "http://store.steampowered.com/app/%d", "http://store.steampowered.com/app/477160"},
+		{"https://user@localhost:8080/api/", "https://user@localhost:8080/app/%d", "https://user@localhost:8080/app/477160"},
+		{"ftp://files.example.com/pub", "ftp://files.example.com/app/%d", "ftp://files.example.com/app/477160"},
+	} {
+		u, err := AppPage.WithBase(test.base)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", test.base, err)
+			continue
+		}
+		if u != test.expected {
+			t.Errorf("expected %s resolved against %q to be %s, got %s", AppPage, test.base, test.expected, u)
+		}
+		if args, err := u.ExtractArgsErr(test.url); err != nil || !reflect.DeepEqual(args, []any{int64(477160)}) {
+			t.Errorf("expected [477160] to be extracted from %q using %s, got %v (%v)", test.url, u, args, err)
+		}
+	}
+	if u, _ := AppPage.WithBase("ftp://files.example.com"); u.Fill(477160) != "ftp://files.example.com/app/477160" {
+		t.Errorf("expected a pinned scheme to be kept when filling, got %q", u.Fill(477160))
+	}
+
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	if u, err := SteamAppPage.WithBase("https://example.com"); err != nil || u != SteamAppPage {
+		t.Errorf("expected an absolute URL format to be returned as is, got %s (%v)", u, err)
+	}
+	for _, base := range []string{"store.steampowered.com", "/relative", "https://%zz"} {
+		if _, err := AppPage.WithBase(base); err == nil {
+			t.Errorf("expected an error for the base URL %q", base)
+		}
+	}
+}
+
+func TestURL_Fill_literalScheme(t *testing.T) {
+	for _, test := range []struct {
+		u        URL
+		expected string
+		other    string
+	}{
+		{"https://store.steampowered.com/app/%d", "https://store.steampowered.com/app/477160", "http://store.steampowered.com/app/477160"},
+		{"http://store.steampowered.com/app/%d", "http://store.steampowered.com/app/477160", "https://store.steampowered.com/app/477160"},
+	} {
+		if filled := test.u.Fill(477160); filled != test.expected {
+			t.Errorf("expected %s filled with 477160 to be %q, got %q", test.u, test.expected, filled)
+		}
+		if args := test.u.ExtractArgs(test.u.Fill(477160)); !reflect.DeepEqual(args, []any{int64(477160)}) {
+			t.Errorf("expected [477160] to be extracted using %s, got %v", test.u, args)
+		}
+		if test.u.Match(test.other) {
+			t.Errorf("expected %s not to match %q, as it has a different scheme", test.u, test.other)
+		}
+	}
+}
+
+func TestURL_FillWithDefaults(t *testing.T) {
+	const SteamSearch URL = "%s://store.steampowered.com/search?term=%s&page=%d"
+	defaults := []any{"all", 1}
+	for _, test := range []struct {
+		args     []any
+		expected string
+	}{
+		{[]any{"portal", nil}, "https://store.steampowered.com/search?term=portal&page=1"},
+		{[]any{nil, 3}, "https://store.steampowered.com/search?term=all&page=3"},
+		{[]any{nil, nil}, "https://store.steampowered.com/search?term=all&page=1"},
+		{[]any{"portal", 3}, "https://store.steampowered.com/search?term=portal&page=3"},
+	} {
+		if filled := SteamSearch.FillWithDefaults(defaults, test.args...); filled != test.expected {
+			t.Errorf("expected %v to fill %s as %q, got %q", test.args, SteamSearch, test.expected, filled)
+		}
+	}
+
+	// Nil args without a default are left as is, so optional sections are still omitted
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d%{?l=%s%}"
+	if filled := SteamAppPage.FillWithDefaults([]any{477160}, nil, nil); filled != "https://store.steampowered.com/app/477160" {
+		t.Errorf("expected the optional section to be omitted, got %q", filled)
+	}
+	if filled := SteamAppPage.FillWithDefaults([]any{nil, "english"}, 620, nil); filled != "https://store.steampowered.com/app/620?l=english" {
+		t.Errorf("expected the optional section to be filled with its default, got %q", filled)
+	}
+}
+
+func TestURL_FillWithQuery(t *testing.T) {
+	extra := url.Values{"utm_source": {"newsletter"}, "l": {"french", "german"}}
+	for _, test := range []struct {
+		u        URL
+		args     []any
+		expected string
+	}{
+		{
+			"%s://store.steampowered.com/app/%d",
+			[]any{477160},
+			"https://store.steampowered.com/app/477160?l=french&l=german&utm_source=newsletter",
+		},
+		{
+			"%s://store.steampowered.com/app/%d?l=%s&cc=%s#reviews",
+			[]any{477160, "english", "uk"},
+			"https://store.steampowered.com/app/477160?cc=uk&l=french&l=german&utm_source=newsletter#reviews",
+		},
+	} {
+		t.Run(string(test.u), func(t *testing.T) {
+			actual, err := test.u.FillWithQuery(extra, test.args...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestURL_relative(t *testing.T) {
+	const RelativeAppReview URL = "/app/%d/reviews?filter=%s"
+	if actual := RelativeAppReview.String(); actual != string(RelativeAppReview) {
+		t.Errorf("expected String to return %q, got %q", RelativeAppReview, actual)
+	}
+	if verbs := RelativeAppReview.Verbs(); len(verbs) != 2 || verbs[0].Verb != "d" {
+		t.Errorf("expected the first verb of a relative format not to be skipped, got %+v", verbs)
+	}
+	if actual := RelativeAppReview.Partial(477160).Fill("recent"); actual != "/app/477160/reviews?filter=recent" {
+		t.Errorf("unexpected partially applied URL %q", actual)
+	}
+	if actual, err := RelativeAppReview.FillValidated(477160, "recent"); err != nil || actual != "/app/477160/reviews?filter=recent" {
+		t.Errorf("unexpected result from FillValidated (%q, %v)", actual, err)
+	}
+}
+
+func TestURL_pinnedScheme(t *testing.T) {
+	for _, test := range []struct {
+		u       URL
+		args    []any
+		filled  string
+		regex   string
+		matched string
+		extract []any
+	}{
+		{
+			u:       "ftp://%s/pub/%s",
+			args:    []any{"ftp.example.com", "readme.txt"},
+			filled:  "ftp://ftp.example.com/pub/readme.txt",
+			regex:   `ftp://((?:[a-zA-Z0-9-._~]|%[0-9A-Fa-f]{2})+)/pub/((?:[a-zA-Z0-9-._~]|%[0-9A-Fa-f]{2})+)`,
+			matched: "ftp://ftp.example.com/pub/readme.txt",
+			extract: []any{"ftp.example.com", "readme.txt"},
+		},
+		{
+			u:       "steam://run/%d",
+			args:    []any{477160},
+			filled:  "steam://run/477160",
+			regex:   `steam://run/([+-]?\d+)`,
+			matched: "steam://run/477160",
+			extract: []any{int64(477160)},
+		},
+		{
+			u:       "mailto:%s?subject=%s",
+			args:    []any{"someone@example.com", "Hello+World"},
+			filled:  "mailto:someone@example.com?subject=Hello+World",
+			regex:   `mailto:((?:[a-zA-Z0-9-._~!$'()*+,;=:@]|%[0-9A-Fa-f]{2})+)\?subject=((?:[a-zA-Z0-9-._~+]|%[0-9A-Fa-f]{2})+)`,
+			matched: "mailto:someone@example.com?subject=Hello+World",
+			extract: []any{"someone@example.com", "Hello World"},
+		},
+		{
+			u:       "tel:%s",
+			args:    []any{"+1-555-0100"},
+			filled:  "tel:+1-555-0100",
+			regex:   `tel:((?:[a-zA-Z0-9-._~!$'()*+,;=:@]|%[0-9A-Fa-f]{2})+)`,
+			matched: "tel:+1-555-0100",
+			extract: []any{"+1-555-0100"},
+		},
+		{
+			// Formats without a protocol that begin with a host and port are not mistaken for an opaque scheme
+			u:       "localhost:%d/app/%d",
+			args:    []any{8080, 5},
+			filled:  "https://localhost:8080/app/5",
+			regex:   `(?:https?:)?//localhost:([+-]?\d+)/app/([+-]?\d+)`,
+			matched: "http://localhost:8080/app/5",
+			extract: []any{int64(8080), int64(5)},
+		},
+	} {
+		t.Run(string(test.u), func(t *testing.T) {
+			if err := test.u.Validate(); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if actual := test.u.Fill(test.args...); actual != test.filled {
+				t.Errorf("expected Fill to return %q, got %q", test.filled, actual)
+			}
+			if actual := test.u.NumVerbs(); actual != len(test.args) {
+				t.Errorf("expected %d verbs, got %d", len(test.args), actual)
+			}
+			if actual := test.u.Regex().String(); actual != test.regex {
+				t.Errorf("expected Regex to return %q, got %q", test.regex, actual)
+			}
+			if actual := test.u.ExtractArgs(test.matched); !reflect.DeepEqual(actual, test.extract) {
+				t.Errorf("expected ExtractArgs to return %v, got %v", test.extract, actual)
+			}
+			if test.u.Match(strings.Replace(test.matched, test.u.pinnedScheme()+":", "https:", 1)) && test.u.pinnedScheme() != "" {
+				t.Errorf("expected %s not to match a URL with a different scheme", test.u)
+			}
+		})
+	}
 }
 
 func ExampleURL_Match() {
@@ -39,6 +434,120 @@ func ExampleURL_Match() {
 	// true
 }
 
+func TestURL_Match_protocolRelative(t *testing.T) {
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	for _, url := range []string{
+		"//store.steampowered.com/app/477160",
+		"http://store.steampowered.com/app/477160",
+		"https://store.steampowered.com/app/477160",
+	} {
+		t.Run(url, func(t *testing.T) {
+			if !SteamAppPage.Match(url) {
+				t.Errorf("expected %s to match %s", url, SteamAppPage)
+			}
+			if !SteamAppPage.MatchExact(url) {
+				t.Errorf("expected %s to match %s exactly", url, SteamAppPage)
+			}
+			if !SteamAppPage.MatchFold(strings.Replace(url, "store", "STORE", 1)) {
+				t.Errorf("expected %s to match %s case-insensitively", url, SteamAppPage)
+			}
+			if args := SteamAppPage.ExtractArgs(url); !reflect.DeepEqual(args, []any{int64(477160)}) {
+				t.Errorf("expected [477160], got %v", args)
+			}
+		})
+	}
+
+	if SteamAppPage.MatchExact("/store.steampowered.com/app/477160") {
+		t.Errorf("expected a URL beginning with a single slash not to match")
+	}
+}
+
+func TestURL_ExtractArgs_ipv6(t *testing.T) {
+	for _, test := range []struct {
+		u        URL
+		url      string
+		expected []any
+	}{
+		{"%s://[%s]/app/%d", "http://[::1]/app/477160", []any{"::1", int64(477160)}},
+		{"%s://[%s]/app/%d", "https://[2001:db8::1]/app/477160", []any{"2001:db8::1", int64(477160)}},
+		{"%s://[%s]:%d/app/%d", "https://[2001:db8::1]:8080/app/477160", []any{"2001:db8::1", int64(8080), int64(477160)}},
+		{"%s://[%s]:%d/app/%d", "https://[::ffff:192.0.2.1]:8080/app/477160", []any{"::ffff:192.0.2.1", int64(8080), int64(477160)}},
+	} {
+		t.Run(test.url, func(t *testing.T) {
+			if actual := test.u.ExtractArgs(test.url); !reflect.DeepEqual(actual, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, actual)
+			}
+			if actual := test.u.Fill(test.expected...); actual != strings.Replace(test.url, "http://", "https://", 1) {
+				t.Errorf("expected %v to be filled as %q, got %q", test.expected, test.url, actual)
+			}
+		})
+	}
+
+	const IPv6AppPage URL = "%s://[%s]:%d/app/%d"
+	if IPv6AppPage.Match("https://localhost:8080/app/477160") {
+		t.Errorf("expected a host without brackets not to match %s", IPv6AppPage)
+	}
+}
+
+func TestURL_MatchScheme(t *testing.T) {
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	for _, test := range []struct {
+		url      string
+		allowed  []string
+		expected bool
+	}{
+		{"http://store.steampowered.com/app/477160", nil, true},
+		{"http://store.steampowered.com/app/477160", []string{"https"}, false},
+		{"http://store.steampowered.com/app/477160", []string{"https", "http"}, true},
+		{"https://store.steampowered.com/app/477160", []string{"https"}, true},
+		{"HTTPS://store.steampowered.com/app/477160", []string{"https"}, true},
+		{"https://store.steampowered.com/app/477160", []string{"HTTPS"}, true},
+		{"//store.steampowered.com/app/477160", []string{"https"}, false},
+		{"https://store.steampowered.com/app/Human_Fall_Flat", []string{"https"}, false},
+	} {
+		t.Run(fmt.Sprintf("%s %v", test.url, test.allowed), func(t *testing.T) {
+			if actual := SteamAppPage.MatchScheme(test.url, test.allowed...); actual != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCleanPath(t *testing.T) {
+	for _, test := range []struct {
+		url      string
+		expected string
+	}{
+		{"https://store.steampowered.com//app//477160", "https://store.steampowered.com/app/477160"},
+		{"https://store.steampowered.com///app/477160//", "https://store.steampowered.com/app/477160/"},
+		{"https://store.steampowered.com/app/./477160/../477160", "https://store.steampowered.com/app/477160"},
+		{"https://store.steampowered.com//app/477160?redirect=https://example.com//a//b#x//y", "https://store.steampowered.com/app/477160?redirect=https://example.com//a//b#x//y"},
+		{"https://store.steampowered.com//search/Fall%2F%20Flat", "https://store.steampowered.com/search/Fall%2F%20Flat"},
+		{"//store.steampowered.com//app//477160", "//store.steampowered.com/app/477160"},
+		{"/app//477160", "/app/477160"},
+		{"https://store.steampowered.com", "https://store.steampowered.com"},
+		{"mailto:someone@example.com", "mailto:someone@example.com"},
+	} {
+		t.Run(test.url, func(t *testing.T) {
+			if actual := CleanPath(test.url); actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	const url = "https://store.steampowered.com//app//477160"
+	if SteamAppPage.Match(url) {
+		t.Errorf("expected %q not to match without cleaning", url)
+	}
+	if !SteamAppPage.MatchClean(url) {
+		t.Errorf("expected %q to match after cleaning", url)
+	}
+	if args, err := SteamAppPage.ExtractArgsClean(url); err != nil || !reflect.DeepEqual(args, []any{int64(477160)}) {
+		t.Errorf("expected [477160], got %v (%v)", args, err)
+	}
+}
+
 func ExampleURL_ExtractArgs() {
 	const (
 		SteamAppPage   URL = "%s://store.steampowered.com/app/%d"
@@ -69,6 +578,67 @@ func ExampleURL_Soup() {
 	// Human: Fall Flat
 }
 
+func ExampleParseSoup() {
+	html := `<html><body><div id="appHubAppName" class="apphub_AppName">Human: Fall Flat</div></body></html>`
+	doc, err := ParseSoup(strings.NewReader(html))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(doc.Find("div", "id", "appHubAppName").Text())
+	fmt.Println(ParseSoupBytes([]byte(html)).Find("div", "class", "apphub_AppName").Text())
+	_, err = ParseSoup(iotest.ErrReader(io.ErrUnexpectedEOF))
+	fmt.Println(err)
+	// Output:
+	// Human: Fall Flat
+	// Human: Fall Flat
+	// could not read HTML: unexpected EOF
+}
+
+func ExampleURL_SoupFrom() {
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	url := "http://store.steampowered.com/app/477160/Human_Fall_Flat/"
+	fmt.Printf("Getting name of app from %s:\n", url)
+	if soup, _, err := SteamAppPage.SoupFrom(url, nil); err != nil {
+		fmt.Printf("Could not get soup for %s, because %s", url, err.Error())
+	} else {
+		fmt.Println(soup.Find("div", "id", "appHubAppName").Text())
+	}
+}
+
+func TestURL_SoupFrom(t *testing.T) {
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `<div id="appHubAppName">%s %s</div>`, r.URL.Path, r.Header.Get("Accept-Language"))
+	}))
+
+	SteamAppPage := URL("%s://" + host + "/app/%d")
+	doc, _, err := SteamAppPage.SoupFrom("http://"+host+"/app/477160/Human_Fall_Flat/", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := doc.Find("div", "id", "appHubAppName").Text(); text != "/app/477160 " {
+		t.Errorf("expected the standardised URL to be fetched, got %q", text)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept-Language", "en")
+	if doc, _, err = SteamAppPage.SoupFrom("https://"+host+"/app/1794680", req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := doc.Find("div", "id", "appHubAppName").Text(); text != "/app/1794680 en" {
+		t.Errorf("expected the headers of the given request to be used, got %q", text)
+	}
+
+	if _, _, err = SteamAppPage.SoupFrom("https://hempuli.itch.io/baba", nil); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected ErrNoMatch, got %v", err)
+	}
+	if _, _, err = SteamAppPage.JSONFrom("https://hempuli.itch.io/baba", nil); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected ErrNoMatch, got %v", err)
+	}
+}
+
 func ExampleURL_JSON() {
 	const SteamAppReviews URL = "%s://store.steampowered.com/appreviews/%d?json=1&cursor=%s&language=%s&day_range=9223372036854775807&num_per_page=%d&review_type=all&purchase_type=%s&filter=%s&start_date=%d&end_date=%d&date_range_type=%s"
 	args := []any{477160, "*", "all", 20, "all", "all", -1, -1, "all"}
@@ -89,3 +659,1806 @@ func ExampleURL_JSON() {
 	// Getting review stats for 477160 from https://store.steampowered.com/appreviews/477160?json=1&cursor=*&language=all&day_range=9223372036854775807&num_per_page=20&review_type=all&purchase_type=all&filter=all&start_date=-1&end_date=-1&date_range_type=all:
 	// [num_reviews review_score review_score_desc total_negative total_positive total_reviews]
 }
+
+func TestJSONInto(t *testing.T) {
+	type querySummary struct {
+		NumReviews    int    `json:"num_reviews"`
+		ReviewScore   int    `json:"review_score"`
+		TotalPositive int    `json:"total_positive"`
+		TotalNegative int    `json:"total_negative"`
+		ReviewDesc    string `json:"review_score_desc"`
+	}
+	type reviews struct {
+		Success      int          `json:"success"`
+		QuerySummary querySummary `json:"query_summary"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"success":1,"query_summary":{"num_reviews":20,"review_score":9,"review_score_desc":"Very Positive","total_positive":10,"total_negative":2}}`))
+	}))
+	defer server.Close()
+
+	const SteamAppReviews URL = "%s://store.steampowered.com/appreviews/%d?json=1"
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, resp, err := JSONInto[reviews](SteamAppReviews, req, 477160)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	expected := reviews{Success: 1, QuerySummary: querySummary{
+		NumReviews:    20,
+		ReviewScore:   9,
+		TotalPositive: 10,
+		TotalNegative: 2,
+		ReviewDesc:    "Very Positive",
+	}}
+	if r != expected {
+		t.Errorf("expected %+v, got %+v", expected, r)
+	}
+
+	if _, _, err = JSONInto[[]int](SteamAppReviews, req, 477160); err == nil {
+		t.Errorf("expected an error when unmarshalling an object into a slice")
+	}
+}
+
+func TestURL_JSONWithHeaders(t *testing.T) {
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"user_agent":%q,"authorization":%q}`, r.UserAgent(), r.Header.Get("Authorization"))
+	}))
+
+	const Page URL = "%s://%s/app/%d"
+	headers := http.Header{}
+	headers.Set("User-Agent", "url-fmt")
+	headers.Set("Authorization", "Bearer token")
+
+	jsonBody, _, err := Page.JSONWithHeaders(nil, headers, host, 477160)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if jsonBody["user_agent"] != "url-fmt" {
+		t.Errorf("expected User-Agent to be %q, got %q", "url-fmt", jsonBody["user_agent"])
+	}
+	if jsonBody["authorization"] != "Bearer token" {
+		t.Errorf("expected Authorization to be %q, got %q", "Bearer token", jsonBody["authorization"])
+	}
+
+	// Headers should not be applied to a caller-supplied request
+	_, req, err := Page.GetRequest(host, 477160)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jsonBody, _, err = Page.JSONWithHeaders(req, headers); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if jsonBody["authorization"] != "" {
+		t.Errorf("expected no Authorization header on caller-supplied request, got %q", jsonBody["authorization"])
+	}
+}
+
+func TestURL_SoupWithHeaders(t *testing.T) {
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `<html><body><div id="appHubAppName">%s</div></body></html>`, r.UserAgent())
+	}))
+
+	const Page URL = "%s://%s/app/%d"
+	doc, _, err := Page.SoupWithHeaders(nil, http.Header{"User-Agent": []string{"url-fmt"}}, host, 477160)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name := doc.Find("div", "id", "appHubAppName").Text(); name != "url-fmt" {
+		t.Errorf("expected User-Agent to be %q, got %q", "url-fmt", name)
+	}
+}
+
+func ExampleURL_FillValidated() {
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+
+	fmt.Println(SteamAppPage.NumVerbs())
+	fmt.Println(SteamAppPage.FillValidated(477160))
+	if _, err := SteamAppPage.FillValidated(); err != nil {
+		fmt.Println(err)
+	}
+	if _, err := SteamAppPage.FillValidated(477160, "Human_Fall_Flat"); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 1
+	// https://store.steampowered.com/app/477160 <nil>
+	// %s://store.steampowered.com/app/%d expects 1 args (excluding the protocol), but 0 were given
+	// %s://store.steampowered.com/app/%d expects 1 args (excluding the protocol), but 2 were given
+}
+
+func TestURL_NumVerbs(t *testing.T) {
+	for _, test := range []struct {
+		u        URL
+		expected int
+	}{
+		{"%s://store.steampowered.com/app/%d", 1},
+		{"https://store.steampowered.com/app/%d", 1},
+		{"%s://%s.itch.io/%s", 2},
+		{"%s://store.steampowered.com/appreviews/%d?json=1&cursor=%s&language=%s&day_range=9223372036854775807&num_per_page=%d&review_type=all&purchase_type=%s&filter=%s&start_date=%d&end_date=%d&date_range_type=%s", 9},
+		{"%s://example.com", 0},
+	} {
+		if actual := test.u.NumVerbs(); actual != test.expected {
+			t.Errorf("%s has %d verbs, expected %d", test.u, actual, test.expected)
+		}
+	}
+}
+
+func ExampleStripFragment() {
+	fmt.Println(StripFragment("https://example.com/page/1#section-intro"))
+	fmt.Println(StripFragment("https://example.com/page/1#"))
+	fmt.Println(StripFragment("https://example.com/page/1#a#b"))
+	fmt.Println(StripFragment("https://example.com/page/1"))
+	// Output:
+	// https://example.com/page/1
+	// https://example.com/page/1
+	// https://example.com/page/1
+	// https://example.com/page/1
+}
+
+func TestURL_Fragment(t *testing.T) {
+	const (
+		Page        URL = "%s://example.com/page/%d"
+		PageQuery   URL = "%s://example.com/page/%d?lang=%s"
+		PageSection URL = "%s://example.com/page/%d#section-%s"
+	)
+
+	for _, test := range []struct {
+		u        URL
+		url      string
+		match    bool
+		expected []any
+	}{
+		{Page, "https://example.com/page/1", true, []any{int64(1)}},
+		{Page, "https://example.com/page/1#section-intro", true, []any{int64(1)}},
+		{Page, "https://example.com/page/1#", true, []any{int64(1)}},
+		{Page, "https://example.com/other#https://example.com/page/1", false, nil},
+		{PageQuery, "https://example.com/page/1?lang=en#top", true, []any{int64(1), "en"}},
+		{PageQuery, "https://example.com/page/1lang=en", false, nil},
+		{PageSection, "https://example.com/page/1#section-intro", true, []any{int64(1), "intro"}},
+		{PageSection, "https://example.com/page/1#section-intro#outro", true, []any{int64(1), "intro"}},
+		{PageSection, "https://example.com/page/1#", false, nil},
+		{PageSection, "https://example.com/page/1", false, nil},
+	} {
+		if match := test.u.Match(test.url); match != test.match {
+			t.Errorf("%s matching %q = %t, expected %t", test.u, test.url, match, test.match)
+		}
+		if test.match {
+			if args := test.u.ExtractArgs(test.url); fmt.Sprint(args) != fmt.Sprint(test.expected) {
+				t.Errorf("extracted %v from %q using %s, expected %v", args, test.url, test.u, test.expected)
+			}
+		}
+	}
+}
+
+func TestURL_ExtractArgs_char(t *testing.T) {
+	const EmojiPage URL = "%s://example.com/emoji/%c/%d"
+	for _, r := range []rune{'a', 'é', '🎮'} {
+		url := EmojiPage.Fill(r, 1)
+		args := EmojiPage.ExtractArgs(url)
+		if len(args) != 2 {
+			t.Fatalf("expected 2 args to be extracted from %q, got %d", url, len(args))
+		}
+		if args[0] != r {
+			t.Errorf("extracted %v (%T) from %q, expected %q", args[0], args[0], url, r)
+		}
+		if args[1] != int64(1) {
+			t.Errorf("extracted %v from %q, expected 1", args[1], url)
+		}
+	}
+}
+
+func TestURL_ExtractArgs_charDelimiters(t *testing.T) {
+	const GradePage URL = "%s://example.com/grade/%c/detail/%d"
+	for _, test := range []struct {
+		url   string
+		grade rune
+		id    int64
+		match bool
+	}{
+		{"https://example.com/grade/A/detail/12", 'A', 12, true},
+		{"https://example.com/grade/é/detail/7", 'é', 7, true},
+		{"https://example.com/grade///detail/12", 0, 0, false},
+		{"https://example.com/grade/?/detail/12", 0, 0, false},
+		{"https://example.com/grade/#/detail/12", 0, 0, false},
+	} {
+		args, err := GradePage.ExtractArgsErr(test.url)
+		switch {
+		case !test.match && err == nil:
+			t.Errorf("expected %q not to match, extracted %v", test.url, args)
+		case test.match && err != nil:
+			t.Errorf("unexpected error for %q: %v", test.url, err)
+		case test.match && (args[0] != test.grade || args[1] != test.id):
+			t.Errorf("extracted %v from %q, expected [%q %d]", args, test.url, test.grade, test.id)
+		}
+	}
+}
+
+func TestURL_rawQuery(t *testing.T) {
+	const ItchIOSearch URL = "%s://itch.io/search?%s"
+	for _, test := range []struct {
+		url      string
+		expected []any
+	}{
+		{"https://itch.io/search?q=baba+is+you&page=2&sort=top", []any{"q=baba+is+you&page=2&sort=top"}},
+		{"https://itch.io/search?q=50%25", []any{"q=50%25"}},
+		{"https://itch.io/search?q=baba#results", []any{"q=baba"}},
+		{"https://itch.io/search?", []any{""}},
+	} {
+		args, err := ItchIOSearch.ExtractArgsErr(test.url)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", test.url, err)
+		} else if !reflect.DeepEqual(args, test.expected) {
+			t.Errorf("expected %q to be extracted from %q, got %q", test.expected, test.url, args)
+		}
+	}
+	if filled := ItchIOSearch.Fill("q=baba&page=2"); filled != "https://itch.io/search?q=baba&page=2" {
+		t.Errorf("expected the raw query to be filled as is, got %q", filled)
+	}
+
+	// String verbs that are not the entire query still capture a single value
+	for _, u := range []URL{"%s://itch.io/search?%s&page=2", "%s://itch.io/search?q=%s", "%s://itch.io/search/%s"} {
+		if verbs := u.Verbs(); verbs[0].Pattern == string(rawQueryStringVerbRegexPattern) {
+			t.Errorf("expected %s not to capture the entire query", u)
+		}
+	}
+	if verbs := ItchIOSearch.Verbs(); verbs[0].Pattern != string(rawQueryStringVerbRegexPattern) {
+		t.Errorf("expected the verb of %s to capture the entire query, got %s", ItchIOSearch, verbs[0].Pattern)
+	}
+}
+
+func TestURL_pathStringVerb(t *testing.T) {
+	const (
+		StrictProfile URL = "%s://itch.io/profile/%s/games"
+		LooseProfile  URL = "%s://itch.io/profile/%#s/games"
+	)
+	for _, slug := range []string{"@hempuli", "baba+is+you", "a,b,c", "it's-(a)-game!", "k=v;x=y&z=$1:*"} {
+		url := LooseProfile.Fill(slug)
+		if url != StrictProfile.Fill(slug) {
+			t.Errorf("expected %%#s to fill the same as %%s, got %q and %q", url, StrictProfile.Fill(slug))
+		}
+		if StrictProfile.Match(url) {
+			t.Errorf("expected %s not to match %q", StrictProfile, url)
+		}
+		args, err := LooseProfile.ExtractArgsErr(url)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", url, err)
+		} else if !reflect.DeepEqual(args, []any{slug}) {
+			t.Errorf("expected %q to be extracted from %q, got %v", slug, url, args)
+		}
+	}
+
+	for _, url := range []string{
+		"https://itch.io/profile/a/b/games",
+		"https://itch.io/profile/a?b/games",
+		"https://itch.io/profile/a#b/games",
+	} {
+		if LooseProfile.Match(url) {
+			t.Errorf("expected %s not to match across a path segment in %q", LooseProfile, url)
+		}
+	}
+	if err := LooseProfile.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestURL_ExtractArgs_percentDecoding(t *testing.T) {
+	const ItchIOSearch URL = "%s://%s.itch.io/%s?q=%s&page=%d"
+	for _, test := range []struct {
+		url      string
+		expected []any
+	}{
+		{"https://hempuli.itch.io/baba%20files%20taxes?q=baba+is+you&page=1", []any{"hempuli", "baba files taxes", "baba is you", int64(1)}},
+		{"https://hempuli.itch.io/baba%2Ffiles?q=50%25+off&page=2", []any{"hempuli", "baba/files", "50% off", int64(2)}},
+		{"https://hempuli.itch.io/baba+files?q=a%2Bb&page=3", nil},
+		{"https://hempuli.itch.io/baba%2520files?q=%2B&page=4", []any{"hempuli", "baba%20files", "+", int64(4)}},
+	} {
+		args, err := ItchIOSearch.ExtractArgsErr(test.url)
+		switch {
+		case test.expected == nil && err == nil:
+			t.Errorf("expected %q not to match, extracted %q", test.url, args)
+		case test.expected != nil && err != nil:
+			t.Errorf("unexpected error for %q: %v", test.url, err)
+		case fmt.Sprintf("%#v", args) != fmt.Sprintf("%#v", test.expected) && test.expected != nil:
+			t.Errorf("extracted %#v from %q, expected %#v", args, test.url, test.expected)
+		}
+	}
+}
+
+func TestURL_ExtractArgs_unicode(t *testing.T) {
+	const CodePointPage URL = "%s://example.com/codepoint/%U"
+	for _, r := range []rune{'a', 'é', '🎮', 0x10FFFF} {
+		url := CodePointPage.Fill(r)
+		if args := CodePointPage.ExtractArgs(url); len(args) != 1 || args[0] != r {
+			t.Errorf("extracted %v from %q, expected [%d]", args, url, r)
+		}
+	}
+}
+
+func TestURL_FillAll(t *testing.T) {
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	appIDs := []int{477160, 620, 400, 70}
+	argSets := make([][]any, len(appIDs))
+	for i, appID := range appIDs {
+		argSets[i] = []any{appID}
+	}
+
+	urls, err := SteamAppPage.FillAll(argSets)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{
+		"https://store.steampowered.com/app/477160",
+		"https://store.steampowered.com/app/620",
+		"https://store.steampowered.com/app/400",
+		"https://store.steampowered.com/app/70",
+	}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("expected %q, got %q", expected, urls)
+	}
+	if results, _ := SteamAppPage.ExtractBatch(urls); !reflect.DeepEqual(results, [][]any{{int64(477160)}, {int64(620)}, {int64(400)}, {int64(70)}}) {
+		t.Errorf("expected the filled URLs to round trip, got %v", results)
+	}
+
+	for _, test := range []struct {
+		argSets [][]any
+		index   string
+	}{
+		{[][]any{{1}, {2, 3}}, "arg set 1:"},
+		{[][]any{{1}, {2}, {}}, "arg set 2:"},
+		{[][]any{{"477160"}}, "arg set 0:"},
+		{[][]any{{1}, {1.5}}, "arg set 1:"},
+	} {
+		if _, err = SteamAppPage.FillAll(test.argSets); err == nil || !strings.HasPrefix(err.Error(), test.index) {
+			t.Errorf("expected an error for %v starting with %q, got %v", test.argSets, test.index, err)
+		}
+	}
+
+	const OptionalLanguage URL = "%s://store.steampowered.com/app/%d%{?l=%s%}"
+	if urls, err = OptionalLanguage.FillAll([][]any{{620, nil}, {620, "english"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(urls, []string{"https://store.steampowered.com/app/620", "https://store.steampowered.com/app/620?l=english"}) {
+		t.Errorf("expected nil args to omit optional sections, got %q", urls)
+	}
+}
+
+func ExampleURL_Example() {
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	fmt.Println(SteamAppPage.Example())
+	fmt.Println(SteamAppPage.Match(SteamAppPage.Example()))
+	// Output:
+	// https://store.steampowered.com/app/123
+	// true
+}
+
+func TestURL_Example(t *testing.T) {
+	for _, test := range []struct {
+		url     URL
+		example string
+	}{
+		{"%s://store.steampowered.com/app/%d", "https://store.steampowered.com/app/123"},
+		{"%s://%s.itch.io/%s", "https://example.itch.io/example"},
+		{"%s://example.com/flags?enabled=%t&mask=%b&mode=%o&char=%c", "https://example.com/flags?enabled=true&mask=101&mode=17&char=a"},
+		{"%s://example.com/%#b/%#o/%O/%#x/%#X/%x/%X/%U", "https://example.com/0b101/017/0o17/0xff/0XFF/ff/FF/U+0041"},
+		{"%s://example.com/%e/%E/%f/%F/%g/%G", "https://example.com/1.5e+06/1.5E+06/1.5/1.5/1.5/1.5"},
+		{"%s://example.com/docs/%*/page", "https://example.com/docs/example/path/page"},
+		{"%s://example.com/search?{tags...}&page=%d", "https://example.com/search?tags=a&page=123"},
+		{"%s://example.com/app/%d%{?l=%s%}", "https://example.com/app/123?l=example"},
+		{"%s://[%s]:%d/", "https://[::1]:123/"},
+		{"/app/%d/reviews", "/app/123/reviews"},
+		{"%s://example.com/%w/%%d", "https://example.com/1/%d"},
+	} {
+		if example := test.url.Example(); example != test.example {
+			t.Errorf("expected %s to produce %q, got %q", test.url, test.example, example)
+		}
+		if !test.url.Match(test.url.Example()) {
+			t.Errorf("expected %s to match its example %q", test.url, test.url.Example())
+		}
+	}
+}
+
+func TestURL_ExtractBatch(t *testing.T) {
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	urls := []string{
+		"https://store.steampowered.com/app/477160",
+		"https://sokpop.itch.io/ballspell",
+		"http://store.steampowered.com/app/620",
+		"https://store.steampowered.com/app/99999999999999999999",
+	}
+	results, errs := SteamAppPage.ExtractBatch(urls)
+	if len(results) != len(urls) || len(errs) != len(urls) {
+		t.Fatalf("expected %d results and errors, got %d and %d", len(urls), len(results), len(errs))
+	}
+
+	expected := [][]any{{int64(477160)}, nil, {int64(620)}, nil}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("expected results %v, got %v", expected, results)
+	}
+	if errs[0] != nil || errs[2] != nil {
+		t.Errorf("unexpected errors for matching URLs: %v, %v", errs[0], errs[2])
+	}
+	if !errors.Is(errs[1], ErrNoMatch) {
+		t.Errorf("expected ErrNoMatch for %q, got %v", urls[1], errs[1])
+	}
+	var parseErr *ParseError
+	if !errors.As(errs[3], &parseErr) {
+		t.Errorf("expected a *ParseError for %q, got %v", urls[3], errs[3])
+	}
+
+	const Invalid URL = "%s://example.com%{:%d/pointer/%p"
+	if _, errs = Invalid.ExtractBatch(urls); errs[0] == nil || errs[3] == nil {
+		t.Errorf("expected every URL to error for an invalid URL format, got %v", errs)
+	}
+}
+
+func ExampleURL_MatchAll() {
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	text := `<ul>
+	<li><a href="https://store.steampowered.com/app/477160/Human_Fall_Flat/">Human: Fall Flat</a></li>
+	<li><a href="https://sokpop.itch.io/ballspell">Ballspell</a></li>
+	<li><a href="http://store.steampowered.com/app/620">Portal 2</a></li>
+	<li>https://store.steampowered.com/app/400 https://store.steampowered.com/app/70</li>
+</ul>`
+
+	fmt.Println(SteamAppPage.MatchAll(text))
+	fmt.Println(SteamAppPage.ExtractAllArgs(text))
+	// Output:
+	// [https://store.steampowered.com/app/477160 http://store.steampowered.com/app/620 https://store.steampowered.com/app/400 https://store.steampowered.com/app/70]
+	// [[477160] [620] [400] [70]]
+}
+
+func TestURL_ScanReader(t *testing.T) {
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	var b strings.Builder
+	expected := make([]string, 0)
+	for i := 0; i < 20000; i++ {
+		// Vary the amount of filler between each URL so that URLs straddle the boundaries between chunks
+		b.WriteString(strings.Repeat("x", i%97))
+		url := SteamAppPage.Fill(i)
+		expected = append(expected, url)
+		b.WriteString(" " + url + "\n")
+	}
+
+	matches, err := SteamAppPage.ScanReader(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(matches, expected) {
+		t.Errorf("expected %d matches, got %d", len(expected), len(matches))
+		for i := 0; i < len(expected) && i < len(matches); i++ {
+			if matches[i] != expected[i] {
+				t.Fatalf("first mismatch at %d: expected %q, got %q", i, expected[i], matches[i])
+			}
+		}
+	}
+
+	if _, err = SteamAppPage.ScanReader(iotest.ErrReader(io.ErrUnexpectedEOF)); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected the error from the reader to be returned, got %v", err)
+	}
+}
+
+func TestURL_FindArgsIndex(t *testing.T) {
+	const SteamAppReviews URL = "%s://store.steampowered.com/app/%d/reviews/%s"
+	text := "Try https://store.steampowered.com/app/477160/reviews/recent, or https://store.steampowered.com/app/1794680/reviews/top!"
+	expected := [][]int{
+		{4, 60, 39, 45, 54, 60},
+		{65, 119, 100, 107, 116, 119},
+	}
+
+	indices := SteamAppReviews.FindArgsIndex(text)
+	if fmt.Sprint(indices) != fmt.Sprint(expected) {
+		t.Fatalf("expected indices %v, got %v", expected, indices)
+	}
+	for i, args := range [][]string{{"477160", "recent"}, {"1794680", "top"}} {
+		for j, arg := range args {
+			if actual := text[indices[i][2+j*2]:indices[i][3+j*2]]; actual != arg {
+				t.Errorf("match %d: expected arg %d to be %q, got %q", i, j, arg, actual)
+			}
+		}
+	}
+
+	// Rewrite each app ID in place, working backwards so that earlier offsets remain valid
+	rewritten := text
+	for i := len(indices) - 1; i >= 0; i-- {
+		rewritten = rewritten[:indices[i][2]] + "NEWID" + rewritten[indices[i][3]:]
+	}
+	if expected := "Try https://store.steampowered.com/app/NEWID/reviews/recent, or https://store.steampowered.com/app/NEWID/reviews/top!"; rewritten != expected {
+		t.Errorf("expected %q, got %q", expected, rewritten)
+	}
+}
+
+func TestURL_UnmarshalText(t *testing.T) {
+	type config struct {
+		AppPage  URL `json:"app_page"`
+		GamePage URL `json:"game_page"`
+	}
+
+	var c config
+	if err := json.Unmarshal([]byte(`{"app_page":"store.steampowered.com/app/%d","game_page":"%s://%s.itch.io/%s"}`), &c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.AppPage != "%s://store.steampowered.com/app/%d" {
+		t.Errorf("expected the protocol of %q to be normalised", c.AppPage)
+	}
+	if c.GamePage != "%s://%s.itch.io/%s" {
+		t.Errorf("expected %q to be unchanged", c.GamePage)
+	}
+
+	var pinned config
+	if err := json.Unmarshal([]byte(`{"app_page":"https://store.steampowered.com/app/%d"}`), &pinned); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pinned.AppPage != "https://store.steampowered.com/app/%d" {
+		t.Errorf("expected the literal scheme of %q to be kept", pinned.AppPage)
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var roundTripped config
+	if err = json.Unmarshal(b, &roundTripped); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if roundTripped != c {
+		t.Errorf("round-tripped %+v, expected %+v", roundTripped, c)
+	}
+
+	if err = json.Unmarshal([]byte(`{"app_page":"%s://store.steampowered.com%{:%d/app/%d"}`), &c); err == nil {
+		t.Errorf("expected an error when unmarshalling a format that produces an invalid regex")
+	}
+}
+
+func TestURL_RegexErr(t *testing.T) {
+	// The optional section is never closed, so its non-capturing group is never closed either
+	const Invalid URL = "%s://example.com%{:%d/pointer/%p"
+	if _, err := Invalid.RegexErr(); err == nil {
+		t.Errorf("expected an error for %s", Invalid)
+	}
+	if _, err := Invalid.MatchErr("https://example.com:80/pointer/0xc000012345"); err == nil {
+		t.Errorf("expected an error when matching using %s", Invalid)
+	}
+	if _, err := Invalid.ExtractArgsErr("https://example.com:80/pointer/0xc000012345"); err == nil {
+		t.Errorf("expected an error when extracting args using %s", Invalid)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected Regex to panic for %s", Invalid)
+			}
+		}()
+		Invalid.Regex()
+	}()
+
+	const Valid URL = "%s://store.steampowered.com/app/%d"
+	if pattern, err := Valid.RegexErr(); err != nil || pattern.String() != Valid.Regex().String() {
+		t.Errorf("expected RegexErr to produce %s, got (%v, %v)", Valid.Regex(), pattern, err)
+	}
+	if ok, err := Valid.MatchErr("https://store.steampowered.com/app/477160"); !ok || err != nil {
+		t.Errorf("expected a match, got (%t, %v)", ok, err)
+	}
+}
+
+func TestURL_generalFloatVerbs(t *testing.T) {
+	for _, u := range []URL{"%s://example.com/price/%g/currency/%s", "%s://example.com/price/%G/currency/%s"} {
+		for _, f := range []float64{1.5, 1e20, 100000, 1e6, -0.00001, 3.14159e-12} {
+			filled := u.Fill(f, "gbp")
+			args, err := u.ExtractArgsErr(filled)
+			if err != nil {
+				t.Fatalf("unexpected error for %q: %v", filled, err)
+			}
+			if !reflect.DeepEqual(args, []any{f, "gbp"}) {
+				t.Errorf("expected [%g gbp] to be extracted from %q, got %v", f, filled, args)
+			}
+		}
+		if err := u.Validate(); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if verbs := u.Verbs(); verbs[0].Kind != reflect.Float64 {
+			t.Errorf("expected the first verb of %s to be a reflect.Float64, got %s", u, verbs[0].Kind)
+		}
+	}
+}
+
+func TestURL_pointerVerb(t *testing.T) {
+	const ObjectPage URL = "%s://example.com/object/%p"
+	value := 5
+	filled := ObjectPage.Fill(&value)
+	args, err := ObjectPage.ExtractArgsErr(filled)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(args, []any{uintptr(unsafe.Pointer(&value))}) {
+		t.Errorf("expected the address of value to be extracted from %q, got %v", filled, args)
+	}
+	if verbs := ObjectPage.Verbs(); len(verbs) != 1 || verbs[0].Kind != reflect.Uintptr {
+		t.Errorf("expected a single reflect.Uintptr verb, got %+v", verbs)
+	}
+
+	// Unknown verbs that are not Perl character classes are matched literally, rather than producing an invalid regex
+	for _, u := range []URL{"%s://example.com/object/%k", "%s://example.com/object/%q"} {
+		if _, err = u.RegexErr(); err != nil {
+			t.Errorf("unexpected error for %s: %v", u, err)
+		}
+		if err = u.Validate(); err == nil {
+			t.Errorf("expected %s to be invalid as it contains an unknown verb", u)
+		}
+	}
+}
+
+func TestURL_wildcardVerb(t *testing.T) {
+	const DocsPage URL = "%s://example.com/docs/%*/page"
+	for _, path := range []string{"a", "a/b", "a/b/c"} {
+		filled := DocsPage.Fill(path)
+		if expected := "https://example.com/docs/" + path + "/page"; filled != expected {
+			t.Errorf("expected %q, got %q", expected, filled)
+		}
+		args, err := DocsPage.ExtractArgsErr(filled)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", filled, err)
+		}
+		if !reflect.DeepEqual(args, []any{path}) {
+			t.Errorf("expected %q to be extracted from %q, got %v", path, filled, args)
+		}
+	}
+	if DocsPage.Match("https://example.com/docs/page") {
+		t.Errorf("expected %s to not match a URL without any intermediate segments", DocsPage)
+	}
+	if err := DocsPage.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if verbs := DocsPage.Verbs(); len(verbs) != 1 || verbs[0].Kind != reflect.String {
+		t.Errorf("expected a single reflect.String verb, got %+v", verbs)
+	}
+}
+
+func TestURL_Validate(t *testing.T) {
+	for _, test := range []struct {
+		u     URL
+		valid bool
+	}{
+		{"%s://store.steampowered.com/app/%d", true},
+		{"https://store.steampowered.com/app/%d", true},
+		{"store.steampowered.com/app/%d", true},
+		{"/app/%d/reviews", true},
+		{"%s://example.com/discount/%d%%", true},
+		{"%s://example.com/id/%#x?redirect=https://example.com", true},
+		{"%s://example.com/search?{tags...}&page=%d", true},
+		{"%s://store.steampowered.com/app/%", false},
+		{"%s://store.steampowered.com/app/%20/%d", false},
+		{"%s://store.steampowered.com/app/%p", true},
+		{"%s://store.steampowered.com/app/%k", false},
+		{"%s://store.steampowered.com/app/%q", false},
+		{"ftp://store.steampowered.com/app/%d", true},
+		{"1ftp://store.steampowered.com/app/%d", false},
+		{"store.steampowered.com/%s://app/%d", false},
+	} {
+		t.Run(string(test.u), func(t *testing.T) {
+			err := test.u.Validate()
+			if test.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if !test.valid && err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestURL_MatchBare(t *testing.T) {
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	for _, test := range []struct {
+		input string
+		match bool
+	}{
+		{"store.steampowered.com/app/477160", true},
+		{"//store.steampowered.com/app/477160", true},
+		{"https://store.steampowered.com/app/477160", true},
+		{"http://store.steampowered.com/app/477160/Human_Fall_Flat/", true},
+		{"see store.steampowered.com/app/477160", false},
+		{"ftp://store.steampowered.com/app/477160", false},
+		{"store.steampowered.com/app/", false},
+	} {
+		if matched := SteamAppPage.MatchBare(test.input); matched != test.match {
+			t.Errorf("expected MatchBare(%q) to be %t, got %t", test.input, test.match, matched)
+		}
+		args, err := SteamAppPage.ExtractArgsBare(test.input)
+		switch {
+		case test.match && (err != nil || !reflect.DeepEqual(args, []any{int64(477160)})):
+			t.Errorf("expected [477160] to be extracted from %q, got %v (%v)", test.input, args, err)
+		case !test.match && err == nil:
+			t.Errorf("expected an error extracting from %q, got %v", test.input, args)
+		}
+	}
+
+	if SteamAppPage.Match("store.steampowered.com/app/477160") {
+		t.Errorf("expected Match to still require a protocol")
+	}
+	const AppPage URL = "/app/%d"
+	if !AppPage.MatchBare("/app/477160") || AppPage.MatchBare("store.steampowered.com/app/477160") {
+		t.Errorf("expected a relative URL format to be anchored to the start of the input")
+	}
+}
+
+func TestURL_ExtractArgsPrefix(t *testing.T) {
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	for _, test := range []struct {
+		url      string
+		expected []any
+		err      bool
+	}{
+		{"https://store.steampowered.com/app/477160/Human_Fall_Flat/", []any{int64(477160)}, false},
+		{"https://store.steampowered.com/app/477160?l=english", []any{int64(477160)}, false},
+		{"https://store.steampowered.com/app/477160#reviews", []any{int64(477160)}, false},
+		{"https://store.steampowered.com/app/477160", []any{int64(477160)}, false},
+		{"https://store.steampowered.com/app/477160abc", nil, true},
+		{"https://example.com/?u=https://store.steampowered.com/app/477160", nil, true},
+	} {
+		t.Run(test.url, func(t *testing.T) {
+			args, err := SteamAppPage.ExtractArgsPrefix(test.url)
+			if test.err {
+				if !errors.Is(err, ErrNoMatch) {
+					t.Errorf("expected an error wrapping ErrNoMatch, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(args, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, args)
+			}
+		})
+	}
+
+	// A URL format that ends with a delimiter does not need to end at a boundary
+	const SteamSearch URL = "%s://store.steampowered.com/search/?term=%s&"
+	if args, err := SteamSearch.ExtractArgsPrefix("https://store.steampowered.com/search/?term=fall&page=2"); err != nil || !reflect.DeepEqual(args, []any{"fall"}) {
+		t.Errorf("expected [fall], got %v (%v)", args, err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	u, err := New("  %s://store.steampowered.com/app/%d\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if u != "%s://store.steampowered.com/app/%d" {
+		t.Errorf("expected the format to be trimmed, got %q", u)
+	}
+
+	for _, malformed := range []string{
+		"",
+		"   ",
+		"%s://store.steampowered.com/app/%",
+		"%s://store.steampowered.com/discount/%d%",
+		"%s:/store.steampowered.com/app/%d",
+		"%s//store.steampowered.com/app/%d",
+		"1ftp://store.steampowered.com/app/%d",
+		"%s://localhost%{:%d/app/%d",
+	} {
+		t.Run(malformed, func(t *testing.T) {
+			if _, err := New(malformed); err == nil {
+				t.Errorf("expected an error")
+			}
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected MustNew to panic")
+				}
+			}()
+			MustNew(malformed)
+		})
+	}
+}
+
+func ExampleURL_RegexBounded() {
+	const ItchIOGamePage URL = "%s://%s.itch.io/%s"
+	pattern, err := ItchIOGamePage.RegexBounded(64)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(pattern)
+	fmt.Println(pattern.MatchString("https://hempuli.itch.io/baba-files-taxes"))
+	fmt.Println(pattern.FindStringSubmatch("https://hempuli.itch.io/" + strings.Repeat("a", 100))[2] == strings.Repeat("a", 64))
+	// Output:
+	// (?:https?:)?//((?:[\-\.0-9A-Z_a-z~]|%[0-9A-Fa-f]{2}){1,64})\.itch\.io/((?:[\-\.0-9A-Z_a-z~]|%[0-9A-Fa-f]{2}){1,64})
+	// true
+	// true
+}
+
+func TestURL_MatchTimeout(t *testing.T) {
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	if !SteamAppPage.MatchTimeout("https://store.steampowered.com/app/477160", time.Second) {
+		t.Errorf("expected match within timeout")
+	}
+	if SteamAppPage.MatchTimeout("https://store.steampowered.com/app/Human_Fall_Flat", time.Second) {
+		t.Errorf("expected no match")
+	}
+	if SteamAppPage.MatchTimeout("https://store.steampowered.com/app/"+strings.Repeat("1", 1<<24), time.Nanosecond) {
+		t.Errorf("expected matching a huge input to time out")
+	}
+}
+
+// pathologicalInput is an input for a format with adjacent string verbs that never matches, as it does not contain
+// the ".example.com" suffix.
+func pathologicalInput(n int) string {
+	return "https://" + strings.Repeat("a-", n)
+}
+
+func BenchmarkURL_Match_pathological(b *testing.B) {
+	const AdjacentVerbs URL = "%s://%s-%s-%s-%s.example.com/%s"
+	pattern := AdjacentVerbs.Regex()
+	bounded, err := AdjacentVerbs.RegexBounded(256)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, n := range []int{1 << 8, 1 << 10, 1 << 12, 1 << 14} {
+		input := pathologicalInput(n)
+		b.Run(fmt.Sprintf("unbounded/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				pattern.MatchString(input)
+			}
+		})
+		b.Run(fmt.Sprintf("bounded/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				bounded.MatchString(input)
+			}
+		})
+	}
+}
+
+func TestURL_ExtractArgs_hex(t *testing.T) {
+	const (
+		ColourPage      URL = "%s://example.com/colour/%x"
+		UpperColourPage URL = "%s://example.com/colour/%X/detail"
+	)
+
+	for _, test := range []struct {
+		u   URL
+		arg any
+	}{
+		{ColourPage, int64(0xff00aa)},
+		{ColourPage, int64(-255)},
+		{ColourPage, 1.0},
+		{ColourPage, -1185.5},
+		{UpperColourPage, int64(0xff00aa)},
+		{UpperColourPage, 0.15625},
+	} {
+		url := test.u.Fill(test.arg)
+		args := test.u.ExtractArgs(url)
+		if len(args) != 1 || args[0] != test.arg {
+			t.Errorf("extracted %v from %q using %s, expected [%v]", args, url, test.u, test.arg)
+		}
+	}
+}
+
+func TestURL_ExtractArgs_signed(t *testing.T) {
+	const Page URL = "%s://example.com/offset/%d/detail"
+	for _, test := range []struct {
+		url      string
+		expected int64
+	}{
+		{"https://example.com/offset/+5/detail", 5},
+		{"https://example.com/offset/-1/detail", -1},
+		{"https://example.com/offset/42/detail", 42},
+		{Page.Fill(-477160), -477160},
+		{URL("%s://example.com/offset/%+d/detail").Fill(5), 5},
+	} {
+		if args, err := Page.ExtractArgsErr(test.url); err != nil {
+			t.Errorf("unexpected error extracting from %q: %v", test.url, err)
+		} else if len(args) != 1 || args[0] != test.expected {
+			t.Errorf("extracted %v from %q, expected [%d]", args, test.url, test.expected)
+		}
+	}
+
+	const SteamAppReviews URL = "%s://store.steampowered.com/appreviews/%d?json=1&language=%s&start_date=%d&end_date=%d"
+	args := []any{int64(477160), "all", int64(-1), int64(-1)}
+	if extracted := SteamAppReviews.ExtractArgs(SteamAppReviews.Fill(args...)); fmt.Sprint(extracted) != fmt.Sprint(args) {
+		t.Errorf("expected %v to round-trip, got %v", args, extracted)
+	}
+}
+
+func TestURL_ExtractArgs_scientific(t *testing.T) {
+	for _, verb := range []string{"%e", "%E"} {
+		u := URL("%s://example.com/value/" + verb + "/detail")
+		for _, f := range []float64{0, 1.5e-10, -1.5e-10, 1.234456e+78, -1.234456e+78, 42} {
+			url := u.Fill(f)
+			args, err := u.ExtractArgsErr(url)
+			if err != nil {
+				t.Errorf("unexpected error extracting from %q using %s: %v", url, u, err)
+			} else if expected, _ := strconv.ParseFloat(fmt.Sprintf(verb, f), 64); len(args) != 1 || args[0] != expected {
+				t.Errorf("extracted %v from %q using %s, expected [%v]", args, url, u, expected)
+			}
+		}
+	}
+}
+
+func TestURL_ExtractArgs_prefixed(t *testing.T) {
+	for _, verb := range []string{"%#x", "%#X", "%#b", "%#o", "%O"} {
+		u := URL("%s://example.com/id/" + verb + "/detail")
+		for _, n := range []int64{0, 1, 10, 255, -255, 1<<40 + 7} {
+			url := u.Fill(n)
+			if expected := "https://example.com/id/" + fmt.Sprintf(verb, n) + "/detail"; url != expected {
+				t.Fatalf("expected %q, got %q", expected, url)
+			}
+
+			args, err := u.ExtractArgsErr(url)
+			if err != nil {
+				t.Errorf("unexpected error extracting from %q using %s: %v", url, u, err)
+			} else if len(args) != 1 || args[0] != n {
+				t.Errorf("extracted %v from %q using %s, expected [%d]", args, url, u, n)
+			}
+		}
+	}
+
+	if verbs := URL("%s://example.com/flags/%#b").Verbs(); len(verbs) != 1 || verbs[0].Verb != "#b" {
+		t.Errorf("expected the # flag to be kept as part of the verb, got %+v", verbs)
+	}
+}
+
+func TestURL_Exists(t *testing.T) {
+	var methods []string
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		switch r.URL.Path {
+		case "/app/1":
+			_, _ = w.Write([]byte("exists"))
+		case "/app/2":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			if r.Header.Get("Range") != "bytes=0-0" {
+				t.Errorf("expected a ranged GET request, got Range: %q", r.Header.Get("Range"))
+			}
+			w.WriteHeader(http.StatusPartialContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	const Page URL = "%s://%s/app/%d"
+	for _, test := range []struct {
+		id       int
+		exists   bool
+		expected []string
+	}{
+		{1, true, []string{http.MethodHead}},
+		{2, true, []string{http.MethodHead, http.MethodGet}},
+		{3, false, []string{http.MethodHead}},
+	} {
+		methods = nil
+		exists, err := Page.Exists(host, test.id)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if exists != test.exists {
+			t.Errorf("app %d: exists = %t, expected %t", test.id, exists, test.exists)
+		}
+		if fmt.Sprint(methods) != fmt.Sprint(test.expected) {
+			t.Errorf("app %d: server received %v, expected %v", test.id, methods, test.expected)
+		}
+	}
+
+	resp, err := Page.Head(nil, host, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Request.Method != http.MethodHead {
+		t.Errorf("expected a 200 response to a HEAD request, got %s to a %s request", resp.Status, resp.Request.Method)
+	}
+
+	// ExistsWithClient uses the given client and request, and each request has the default timeout
+	transport := http.DefaultTransport
+	client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if _, ok := req.Context().Deadline(); !ok {
+			t.Errorf("expected the %s request to have a deadline", req.Method)
+		}
+		if req.Header.Get("User-Agent") != "urlfmt" {
+			t.Errorf("expected the headers of the given request to be used, got %v", req.Header)
+		}
+		return transport.RoundTrip(req)
+	})}
+	req, err := http.NewRequest(http.MethodPost, Page.Fill(host, 2), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	req.Header.Set("User-Agent", "urlfmt")
+	methods = nil
+	if exists, err := Page.ExistsWithClient(client, req); err != nil || !exists {
+		t.Errorf("expected app 2 to exist using ExistsWithClient, got %t and %v", exists, err)
+	}
+	if fmt.Sprint(methods) != fmt.Sprint([]string{http.MethodHead, http.MethodGet}) {
+		t.Errorf("expected a HEAD then a GET request, got %v", methods)
+	}
+	if req.Method != http.MethodPost || req.Header.Get("Range") != "" {
+		t.Errorf("expected the given request to be left untouched, got %s with %v", req.Method, req.Header)
+	}
+}
+
+func TestURL_MatchExact(t *testing.T) {
+	const (
+		SteamAppPage      URL = "%s://store.steampowered.com/app/%d"
+		SteamAppPageSlash URL = "%s://store.steampowered.com/app/%d/"
+	)
+
+	for _, test := range []struct {
+		u     URL
+		url   string
+		match bool
+	}{
+		{SteamAppPage, "https://store.steampowered.com/app/477160", true},
+		{SteamAppPage, "https://store.steampowered.com/app/477160/", true},
+		{SteamAppPage, "https://store.steampowered.com/app/477160/#reviews", true},
+		{SteamAppPage, "https://store.steampowered.com/app/477160//", false},
+		{SteamAppPage, "https://store.steampowered.com/app/477160/Human_Fall_Flat/", false},
+		{SteamAppPage, "see https://store.steampowered.com/app/477160", false},
+		{SteamAppPageSlash, "https://store.steampowered.com/app/477160/", true},
+		{SteamAppPageSlash, "https://store.steampowered.com/app/477160", false},
+	} {
+		if match := test.u.MatchExact(test.url); match != test.match {
+			t.Errorf("%s exactly matching %q = %t, expected %t", test.u, test.url, match, test.match)
+		}
+		if args, err := test.u.ExtractArgsExact(test.url); test.match && (err != nil || args[0] != int64(477160)) {
+			t.Errorf("extracted %v, %v from %q, expected [477160]", args, err, test.url)
+		} else if !test.match && !errors.Is(err, ErrNoMatch) {
+			t.Errorf("expected ErrNoMatch when extracting from %q, got %v", test.url, err)
+		}
+	}
+}
+
+func TestURL_MatchFold(t *testing.T) {
+	const (
+		SteamAppPage   URL = "%s://store.steampowered.com/app/%d"
+		ItchIOGamePage URL = "%s://%s.itch.io/%s"
+		MixedCasePage  URL = "%s://Example.COM/Page/%s"
+	)
+
+	for _, test := range []struct {
+		u        URL
+		url      string
+		match    bool
+		expected []any
+	}{
+		{SteamAppPage, "HTTPS://Store.SteamPowered.com/app/477160", true, []any{int64(477160)}},
+		{SteamAppPage, "https://store.steampowered.com/APP/477160", false, nil},
+		{ItchIOGamePage, "Https://Hempuli.ITCH.io/Baba-Files-Taxes", true, []any{"hempuli", "Baba-Files-Taxes"}},
+		{MixedCasePage, "https://example.com/Page/Slug", true, []any{"Slug"}},
+		{MixedCasePage, "https://example.com/page/Slug", false, nil},
+	} {
+		if match := test.u.MatchFold(test.url); match != test.match {
+			t.Errorf("%s matching %q = %t, expected %t", test.u, test.url, match, test.match)
+		}
+		if test.match {
+			if args, err := test.u.ExtractArgsFold(test.url); err != nil || fmt.Sprint(args) != fmt.Sprint(test.expected) {
+				t.Errorf("extracted %v, %v from %q, expected %v", args, err, test.url, test.expected)
+			}
+		}
+	}
+
+	if SteamAppPage.Match("HTTPS://Store.SteamPowered.com/app/477160") {
+		t.Errorf("expected Match to remain case-sensitive")
+	}
+}
+
+func ExampleURL_Partial() {
+	const SteamAppReviews URL = "%s://store.steampowered.com/appreviews/%d?json=1&cursor=%s&num_per_page=%d"
+
+	base := SteamAppReviews.Partial(477160)
+	fmt.Println(base)
+	fmt.Println(base.Fill("*", 20))
+	fmt.Println(base.Fill("AoJ4", 100))
+	fmt.Println(SteamAppReviews.Partial(477160, "50%").Fill(20))
+	// Output:
+	// %s://store.steampowered.com/appreviews/477160?json=1&cursor=%s&num_per_page=%d
+	// https://store.steampowered.com/appreviews/477160?json=1&cursor=*&num_per_page=20
+	// https://store.steampowered.com/appreviews/477160?json=1&cursor=AoJ4&num_per_page=100
+	// https://store.steampowered.com/appreviews/477160?json=1&cursor=50%&num_per_page=20
+}
+
+func ExampleURL_Verbs() {
+	const SteamAppReviews URL = "%s://store.steampowered.com/appreviews/%d?json=1&cursor=%s&filter_offtopic=%t&score=%f"
+	for _, verb := range SteamAppReviews.Verbs() {
+		fmt.Printf("%%%s at %d: %s -> %s\n", verb.Verb, verb.Offset, verb.Pattern, verb.Kind)
+	}
+	// Output:
+	// %d at 39: ([+-]?\d+) -> int64
+	// %s at 56: ((?:[a-zA-Z0-9-._~+]|%[0-9A-Fa-f]{2})+) -> string
+	// %t at 75: (true|false) -> bool
+	// %f at 84: ([+-]?[0-9]+\.[0-9]+) -> float64
+}
+
+func TestURL_JSONStream(t *testing.T) {
+	const n = 10000
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"total":` + fmt.Sprint(n) + `,"reviews":[`))
+		for i := 0; i < n; i++ {
+			if i > 0 {
+				_, _ = w.Write([]byte(","))
+			}
+			_, _ = fmt.Fprintf(w, `{"id":%d}`, i)
+		}
+		_, _ = w.Write([]byte("]}"))
+	}))
+
+	const Page URL = "%s://%s/appreviews/%d"
+
+	t.Run("JSONStream", func(t *testing.T) {
+		decoder, resp, err := Page.JSONStream(nil, host, 477160)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer resp.Body.Close()
+
+		// Walk tokens until we reach the start of the reviews array
+		for {
+			tok, err := decoder.Token()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tok == "reviews" {
+				break
+			}
+		}
+		if _, err = decoder.Token(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		count := 0
+		for decoder.More() {
+			var review struct {
+				ID int `json:"id"`
+			}
+			if err = decoder.Decode(&review); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if review.ID != count {
+				t.Errorf("expected review %d, got %d", count, review.ID)
+			}
+			count++
+		}
+		if count != n {
+			t.Errorf("expected %d reviews, got %d", n, count)
+		}
+	})
+
+	t.Run("JSONDecode", func(t *testing.T) {
+		jsonBody, _, err := Page.JSONDecode(nil, host, 477160)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if jsonBody["total"] != float64(n) || len(jsonBody["reviews"].([]any)) != n {
+			t.Errorf("expected %d reviews, got %v", n, jsonBody["total"])
+		}
+	})
+}
+
+func TestPostJSONInto(t *testing.T) {
+	type payload struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected method %s, got %s", http.MethodPost, r.Method)
+		}
+		if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+			t.Errorf("expected Content-Type application/json, got %q", contentType)
+		}
+
+		var p payload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Errorf("could not decode request body: %v", err)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"path": r.URL.Path, "received": p})
+	}))
+
+	const Endpoint URL = "%s://%s/apps/%d"
+	sent := payload{Name: "Vampire Survivors", Tags: []string{"roguelite", "bullet hell"}}
+	response, resp, err := PostJSONInto[struct {
+		Path     string  `json:"path"`
+		Received payload `json:"received"`
+	}](Endpoint, sent, host, 1794680)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+	if response.Path != "/apps/1794680" {
+		t.Errorf("expected path /apps/1794680, got %q", response.Path)
+	}
+	if response.Received.Name != sent.Name || strings.Join(response.Received.Tags, ",") != strings.Join(sent.Tags, ",") {
+		t.Errorf("expected %+v to arrive intact, got %+v", sent, response.Received)
+	}
+
+	if _, _, err = Endpoint.PostJSON(make(chan int), host, 1); err == nil {
+		t.Errorf("expected an error for a payload that cannot be marshalled")
+	}
+}
+
+func TestURL_RequestTemplated(t *testing.T) {
+	type payload struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"method":       r.Method,
+			"path":         r.URL.Path,
+			"content_type": r.Header.Get("Content-Type"),
+			"body":         string(body),
+		})
+	}))
+
+	const Endpoint URL = "%s://%s/apps/%d"
+	_, req, err := Endpoint.RequestTemplated(
+		http.MethodPost, `{"name": "%s", "count": %d}`,
+		[]any{host, 1794680}, []any{`Vampire "Survivors"`, 3},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response, _, err := JSONInto[map[string]string](Endpoint, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response["method"] != http.MethodPost || response["path"] != "/apps/1794680" {
+		t.Errorf("expected POST to /apps/1794680, got %s to %s", response["method"], response["path"])
+	}
+	if response["content_type"] != "application/json" {
+		t.Errorf("expected Content-Type application/json, got %q", response["content_type"])
+	}
+	var p payload
+	if err = json.Unmarshal([]byte(response["body"]), &p); err != nil {
+		t.Fatalf("expected a valid JSON body, got %q: %v", response["body"], err)
+	}
+	if p != (payload{Name: `Vampire "Survivors"`, Count: 3}) {
+		t.Errorf("expected the body args to arrive intact, got %+v", p)
+	}
+
+	if _, req, err = Endpoint.RequestTemplated(
+		http.MethodPut, "name=%s&count=%d", []any{host, 1}, []any{"a&b c", 2},
+	); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response, _, err = JSONInto[map[string]string](Endpoint, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response["content_type"] != "application/x-www-form-urlencoded" {
+		t.Errorf("expected Content-Type application/x-www-form-urlencoded, got %q", response["content_type"])
+	}
+	if response["body"] != "name=a%26b+c&count=2" {
+		t.Errorf("expected the body args to be query escaped, got %q", response["body"])
+	}
+}
+
+func ExampleURL_Remap() {
+	const (
+		SteamAppPage    URL = "%s://store.steampowered.com/app/%d"
+		SteamAppReviews URL = "%s://store.steampowered.com/appreviews/%d?json=1"
+	)
+	fmt.Println(SteamAppPage.Remap(SteamAppReviews, "https://store.steampowered.com/app/477160"))
+	// Output:
+	// https://store.steampowered.com/appreviews/477160?json=1 <nil>
+}
+
+func TestURL_Remap(t *testing.T) {
+	const (
+		SteamAppPage    URL = "%s://store.steampowered.com/app/%d"
+		SteamAppReviews URL = "%s://store.steampowered.com/appreviews/%d?json=1"
+		ItchIOGamePage  URL = "%s://%s.itch.io/%s"
+		ItchIODevPage   URL = "%s://%s.itch.io"
+		ItchIOBadPage   URL = "%s://%d.itch.io"
+	)
+
+	for _, test := range []struct {
+		from, to URL
+		url      string
+		expected string
+		err      error
+	}{
+		{SteamAppPage, SteamAppReviews, "https://store.steampowered.com/app/477160", "https://store.steampowered.com/appreviews/477160?json=1", nil},
+		{ItchIOGamePage, ItchIODevPage, "https://hempuli.itch.io/baba", "https://hempuli.itch.io", nil},
+		{ItchIODevPage, ItchIOGamePage, "https://hempuli.itch.io", "", nil},
+		{ItchIOGamePage, ItchIOBadPage, "https://hempuli.itch.io/baba", "", nil},
+		{SteamAppPage, SteamAppReviews, "https://hempuli.itch.io/baba", "", ErrNoMatch},
+	} {
+		t.Run(fmt.Sprintf("%s->%s", test.from, test.to), func(t *testing.T) {
+			actual, err := test.from.Remap(test.to, test.url)
+			switch {
+			case test.expected == "" && err == nil:
+				t.Errorf("expected an error, got %q", actual)
+			case test.expected != "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case test.err != nil && !errors.Is(err, test.err):
+				t.Errorf("expected error to be %v, got %v", test.err, err)
+			case actual != test.expected:
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestURL_repeated(t *testing.T) {
+	const SteamSearch URL = "%s://store.steampowered.com/search?{tags...}&page=%d"
+	for _, test := range []struct {
+		tags     []string
+		expected string
+	}{
+		{[]string{}, "https://store.steampowered.com/search?&page=2"},
+		{[]string{"rpg"}, "https://store.steampowered.com/search?tags=rpg&page=2"},
+		{[]string{"rpg", "co-op", "4 player"}, "https://store.steampowered.com/search?tags=rpg&tags=co-op&tags=4+player&page=2"},
+	} {
+		t.Run(fmt.Sprintf("%d", len(test.tags)), func(t *testing.T) {
+			actual := SteamSearch.Fill(test.tags, 2)
+			if actual != test.expected {
+				t.Fatalf("expected %q, got %q", test.expected, actual)
+			}
+
+			args, err := SteamSearch.ExtractArgsErr(actual)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(args) != 2 {
+				t.Fatalf("expected 2 args, got %v", args)
+			}
+			if tags, ok := args[0].([]string); !ok || strings.Join(tags, ",") != strings.Join(test.tags, ",") {
+				t.Errorf("expected tags %q, got %#v", test.tags, args[0])
+			}
+			if args[1] != int64(2) {
+				t.Errorf("expected page 2, got %#v", args[1])
+			}
+		})
+	}
+
+	if verbs := SteamSearch.Verbs(); len(verbs) != 2 || verbs[0].Verb != "{tags...}" || verbs[0].Kind != reflect.Slice {
+		t.Errorf("expected the repeated marker to be reported as a slice verb, got %+v", verbs)
+	}
+	if actual := SteamSearch.Partial([]string{"rpg", "co-op"}).Fill(3); actual != "https://store.steampowered.com/search?tags=rpg&tags=co-op&page=3" {
+		t.Errorf("unexpected partially applied URL %q", actual)
+	}
+}
+
+func TestCaptureGroups(t *testing.T) {
+	for _, test := range []struct {
+		source   string
+		expected []string
+	}{
+		{`https?://store\.steampowered\.com/app/(\d+)`, []string{`(\d+)`}},
+		{`a/\(literal\)/([a-z]+)/(\d+)`, []string{`([a-z]+)`, `(\d+)`}},
+		{`((?:a=[^&#]*(?:&a=[^&#]*)*)?)`, []string{`((?:a=[^&#]*(?:&a=[^&#]*)*)?)`}},
+		{`([()]+)(?P<name>x(y))`, []string{`([()]+)`, `(?P<name>x(y))`, `(y)`}},
+		{`[](]+(\d)`, []string{`(\d)`}},
+		{
+			`(?:https?:)?//example\.com/price/([+-]?[0-9]+(?:\.[0-9]+)?(?:e[+-][0-9]+)?)`,
+			[]string{`([+-]?[0-9]+(?:\.[0-9]+)?(?:e[+-][0-9]+)?)`},
+		},
+		{`(?i:a)(b(?:c(d))?)`, []string{`(b(?:c(d))?)`, `(d)`}},
+	} {
+		if actual := captureGroups(test.source); strings.Join(actual, " ") != strings.Join(test.expected, " ") {
+			t.Errorf("%s: expected %q, got %q", test.source, test.expected, actual)
+		}
+	}
+}
+
+func TestURL_ExtractArgs_nonCapturingGroups(t *testing.T) {
+	if err := RegisterVerb('r', `(v(?:\d+\.)*\d+(?:-(?:alpha|beta))?)`, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	const ReleaseDownload URL = "%s://example.com/releases/%r/download/%g"
+	for _, test := range []struct {
+		url      string
+		expected []any
+	}{
+		{"https://example.com/releases/v1.2.3/download/1.5", []any{"v1.2.3", 1.5}},
+		{"//example.com/releases/v10-beta/download/1e+20", []any{"v10-beta", 1e20}},
+		{"http://example.com/releases/v2.0-alpha/download/100000", []any{"v2.0-alpha", float64(100000)}},
+	} {
+		args, err := ReleaseDownload.ExtractArgsErr(test.url)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %v", test.url, err)
+		} else if !reflect.DeepEqual(args, test.expected) {
+			t.Errorf("expected %v to be extracted from %q, got %v", test.expected, test.url, args)
+		}
+	}
+	if verbCount, groupPatterns := ReleaseDownload.GroupInfo(); verbCount != len(groupPatterns) {
+		t.Errorf("expected %s to have as many groups as verbs, got %d and %q", ReleaseDownload, verbCount, groupPatterns)
+	}
+}
+
+func TestURL_Timeout(t *testing.T) {
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+
+	const Page URL = "%s://%s/app/%d"
+	for _, test := range []struct {
+		name  string
+		fetch func() error
+	}{
+		{"SoupTimeout", func() error {
+			_, _, err := Page.SoupTimeout(100*time.Millisecond, host, 477160)
+			return err
+		}},
+		{"JSONTimeout", func() error {
+			_, _, err := Page.JSONTimeout(100*time.Millisecond, host, 477160)
+			return err
+		}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			start := time.Now()
+			err := test.fetch()
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected a context deadline error, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("expected the request to be cancelled after 100ms, took %s", elapsed)
+			}
+		})
+	}
+}
+
+// failingBody is an io.ReadCloser that fails both reading and closing.
+type failingBody struct{}
+
+func (failingBody) Read(p []byte) (int, error) { return 0, errors.New("read failed") }
+
+func (failingBody) Close() error { return errors.New("close failed") }
+
+// roundTripperFunc allows a function to be used as a http.RoundTripper.
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestURL_fetch(t *testing.T) {
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Status: "200 OK", Header: http.Header{}, Body: failingBody{}}, nil
+	})
+	defer func() { http.DefaultTransport = defaultTransport }()
+
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	_, resp, err := SteamAppPage.fetch(pageFetchMessages, nil, 477160)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	for _, expected := range []string{"read failed", "close failed"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error %q to contain %q", err.Error(), expected)
+		}
+	}
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) || fetchErr.StatusCode != http.StatusOK {
+		t.Errorf("expected a *FetchError with status 200, got %v", err)
+	}
+	if resp == nil || resp.Request == nil || resp.Request.URL.String() != SteamAppPage.Fill(477160) {
+		t.Errorf("expected the response to refer to the request for %s", SteamAppPage.Fill(477160))
+	}
+
+	if _, _, err = SteamAppPage.JSON(nil, 477160); err == nil || !strings.Contains(err.Error(), "close failed") {
+		t.Errorf("expected JSON to merge the close error, got %v", err)
+	}
+
+	// Each caller describes what it was fetching in its errors
+	for _, test := range []struct {
+		name     string
+		fetch    func() error
+		expected []string
+	}{
+		{
+			"Soup",
+			func() error { _, _, err := SteamAppPage.Soup(nil, 477160); return err },
+			[]string{"could not read response body to https://", "could not close response body to https://"},
+		},
+		{
+			"JSON",
+			func() error { _, _, err := SteamAppPage.JSON(nil, 477160); return err },
+			[]string{`JSON request body from "https://`, `request body for JSON fetched from "https://`},
+		},
+		{
+			"JSONInto",
+			func() error { _, _, err := JSONInto[map[string]any](SteamAppPage, nil, 477160); return err },
+			[]string{`JSON request body from "https://`, `request body for JSON fetched from "https://`},
+		},
+	} {
+		err = test.fetch()
+		for _, expected := range test.expected {
+			if err == nil || !strings.Contains(err.Error(), expected) {
+				t.Errorf("%s: expected error %v to contain %q", test.name, err, expected)
+			}
+		}
+	}
+
+	http.DefaultTransport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("dial failed")
+	})
+	if _, _, err = SteamAppPage.Soup(nil, 477160); err == nil || !strings.Contains(err.Error(), "could not get page https://") {
+		t.Errorf("expected Soup to describe the page it could not get, got %v", err)
+	}
+	if _, _, err = SteamAppPage.JSON(nil, 477160); err == nil || !strings.Contains(err.Error(), `JSON could not be fetched from "https://`) {
+		t.Errorf("expected JSON to describe the JSON it could not fetch, got %v", err)
+	}
+}
+
+func TestURL_fetch_compressed(t *testing.T) {
+	const expected = `{"477160":{"success":true}}`
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var (
+			buf        bytes.Buffer
+			compressor io.WriteCloser
+		)
+		encoding := r.URL.Query().Get("encoding")
+		switch encoding {
+		case "gzip":
+			compressor = gzip.NewWriter(&buf)
+		case "deflate":
+			compressor = zlib.NewWriter(&buf)
+		case "raw-deflate":
+			compressor, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+			encoding = "deflate"
+		default:
+			_, _ = fmt.Fprint(w, expected)
+			return
+		}
+		_, _ = compressor.Write([]byte(expected))
+		_ = compressor.Close()
+		w.Header().Set("Content-Encoding", encoding)
+		_, _ = w.Write(buf.Bytes())
+	}))
+
+	const AppDetails URL = "%s://%s/api/appdetails?encoding=%s"
+	for _, encoding := range []string{"gzip", "deflate", "raw-deflate", "identity"} {
+		t.Run(encoding, func(t *testing.T) {
+			body, _, err := AppDetails.fetchWith(nil, jsonFetchMessages, nil, host, encoding)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(body) != expected {
+				t.Errorf("expected %q, got %q", expected, body)
+			}
+
+			// Setting Accept-Encoding explicitly stops the transport from decompressing the response itself
+			headers := http.Header{"Accept-Encoding": {"gzip, deflate"}}
+			jsonBody, _, err := AppDetails.JSONWithHeaders(nil, headers, host, encoding)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, ok := jsonBody["477160"]; !ok {
+				t.Errorf("unexpected JSON %v", jsonBody)
+			}
+
+			_, req, err := AppDetails.GetRequestWithHeaders(headers, host, encoding)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if jsonBody, _, err = AppDetails.JSONDecode(req); err != nil {
+				t.Fatalf("unexpected error from JSONDecode: %v", err)
+			}
+			if _, ok := jsonBody["477160"]; !ok {
+				t.Errorf("unexpected JSON from JSONDecode %v", jsonBody)
+			}
+
+			decoder, resp, err := AppDetails.JSONStream(req)
+			if err != nil {
+				t.Fatalf("unexpected error from JSONStream: %v", err)
+			}
+			jsonBody = make(map[string]any)
+			if err = decoder.Decode(&jsonBody); err != nil {
+				t.Errorf("unexpected error decoding from JSONStream: %v", err)
+			} else if _, ok := jsonBody["477160"]; !ok {
+				t.Errorf("unexpected JSON from JSONStream %v", jsonBody)
+			}
+			if err = resp.Body.Close(); err != nil {
+				t.Errorf("unexpected error closing the body from JSONStream: %v", err)
+			}
+		})
+	}
+}
+
+func TestURL_EquivalentTo(t *testing.T) {
+	const SteamAppPage URL = "%s://store.steampowered.com/app/%d"
+	for _, test := range []struct {
+		other    URL
+		expected bool
+	}{
+		{"%s://store.steampowered.com/app/%d", true},
+		{"https://store.steampowered.com/app/%d", false},
+		{"http://store.steampowered.com/app/%d", false},
+		{"store.steampowered.com/app/%d", true},
+		{"%s://store.steampowered.com/app/%s", false},
+		{"%s://store.steampowered.com/app/%x", false},
+		{"%s://store.steampowered.com/apps/%d", false},
+		{"%s://store.steampowered.com/app/%d/", false},
+		{"ftp://store.steampowered.com/app/%d", false},
+		{"/app/%d", false},
+	} {
+		t.Run(string(test.other), func(t *testing.T) {
+			if actual := SteamAppPage.EquivalentTo(test.other); actual != test.expected {
+				t.Errorf("expected %t, got %t", test.expected, actual)
+			}
+			if actual := test.other.EquivalentTo(SteamAppPage); actual != test.expected {
+				t.Errorf("expected EquivalentTo to be symmetric")
+			}
+		})
+	}
+}
+
+func TestURL_JSONRaw(t *testing.T) {
+	const response = `{"477160": {"success": true, "data": {"name": "Human: Fall Flat"}}}`
+	_, host := newTLSServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, response)
+	}))
+
+	const AppDetails URL = "%s://%s/api/appdetails?appids=%d"
+	body, jsonBody, resp, err := AppDetails.JSONRaw(nil, host, 477160)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(body) != response {
+		t.Errorf("expected raw body %q, got %q", response, body)
+	}
+	if _, ok := jsonBody["477160"]; !ok {
+		t.Errorf("unexpected JSON %v", jsonBody)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var details map[string]struct {
+		Data struct {
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	if err = json.Unmarshal(body, &details); err != nil || details["477160"].Data.Name != "Human: Fall Flat" {
+		t.Errorf("expected the raw body to be re-parsable, got %+v (%v)", details, err)
+	}
+}
+
+func TestURL_Normalize(t *testing.T) {
+	const SteamAppReviews URL = "%s://store.steampowered.com/appreviews/%d?json=1&cursor=%s&language=%s&num_per_page=%d"
+	expected := "https://store.steampowered.com/appreviews/477160?cursor=AoJ4&json=1&language=all&num_per_page=20"
+	for _, url := range []string{
+		"https://store.steampowered.com/appreviews/477160?json=1&cursor=AoJ4&language=all&num_per_page=20",
+		"https://store.steampowered.com/appreviews/477160?num_per_page=20&language=all&cursor=AoJ4&json=1",
+		"HTTPS://Store.SteamPowered.com:443/appreviews/477160?language=all&json=1&num_per_page=20&cursor=AoJ4",
+		"https://store.steampowered.com/appreviews/477160?cursor=AoJ4&utm_source=x&json=1&language=all&num_per_page=20",
+	} {
+		normalized, err := SteamAppReviews.Normalize(url)
+		if err != nil {
+			t.Errorf("unexpected error normalizing %q: %v", url, err)
+		} else if normalized != expected {
+			t.Errorf("expected %q to normalize to %q, got %q", url, expected, normalized)
+		}
+	}
+
+	if normalized, err := SteamAppReviews.Normalize("http://store.steampowered.com:80/appreviews/5?json=1&cursor=AoJ4&language=all&num_per_page=1"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if normalized != "http://store.steampowered.com/appreviews/5?cursor=AoJ4&json=1&language=all&num_per_page=1" {
+		t.Errorf("expected the scheme to be kept and the default port removed, got %q", normalized)
+	}
+
+	if _, err := SteamAppReviews.Normalize("https://hempuli.itch.io/baba"); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("expected ErrNoMatch, got %v", err)
+	}
+}
+
+// roundTripVerbs are the verbs that are guaranteed to round trip through URL.Fill and URL.ExtractArgs (see
+// URL.ExtractArgs), along with a function that generates an arg for the verb from the fuzzed inputs. The function
+// returns the arg, the value that URL.ExtractArgs should extract, and false if the inputs cannot produce an arg that is
+// reasonable for the verb.
+var roundTripVerbs = map[string]func(i int64, f float64, s string, b bool) (arg any, want any, ok bool){
+	"d":  roundTripInt,
+	"b":  roundTripInt,
+	"o":  roundTripInt,
+	"O":  roundTripInt,
+	"x":  roundTripInt,
+	"X":  roundTripInt,
+	"#b": roundTripInt,
+	"#o": roundTripInt,
+	"#x": roundTripInt,
+	"#X": roundTripInt,
+	"e":  roundTripFloat("e"),
+	"E":  roundTripFloat("E"),
+	"f":  roundTripFloat("f"),
+	"F":  roundTripFloat("F"),
+	"g":  roundTripFloat("g"),
+	"G":  roundTripFloat("G"),
+	"t": func(i int64, f float64, s string, b bool) (any, any, bool) {
+		return b, b, true
+	},
+	"c": func(i int64, f float64, s string, b bool) (any, any, bool) {
+		r := rune(i)
+		return r, r, utf8.ValidRune(r) && !strings.ContainsRune("/?#&", r)
+	},
+	"U": func(i int64, f float64, s string, b bool) (any, any, bool) {
+		r := rune(i)
+		return r, r, utf8.ValidRune(r)
+	},
+	"s": roundTripString(func(r rune) bool {
+		return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-._~", r))
+	}),
+	"#s": roundTripString(func(r rune) bool {
+		return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-._~!$&'()*+,;=:@", r))
+	}),
+	"*": func(i int64, f float64, s string, b bool) (any, any, bool) {
+		// The wildcard capture stops at the first "/b", which terminates it in the format used by FuzzRoundTrip
+		arg, want, ok := roundTripString(func(r rune) bool {
+			return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-._~/", r))
+		})(i, f, s, b)
+		return arg, want, ok && !strings.Contains(arg.(string), "/b")
+	},
+}
+
+func roundTripInt(i int64, f float64, s string, b bool) (any, any, bool) {
+	return i, i, true
+}
+
+// roundTripFloat returns a generator for the given float verb. The verbs with a fixed precision (e.g. "%f") round the
+// arg, so the value that should be extracted is the arg rounded in the same way that fmt does.
+func roundTripFloat(verb string) func(i int64, f float64, s string, b bool) (any, any, bool) {
+	return func(i int64, f float64, s string, b bool) (any, any, bool) {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, nil, false
+		}
+		want, err := strconv.ParseFloat(fmt.Sprintf("%"+verb, f), 64)
+		return f, want, err == nil
+	}
+}
+
+// roundTripString returns a generator for a string verb that only accepts the given characters without percent-encoding
+// them. Fill does not escape string args, so only non-empty strings made up of these characters round trip.
+func roundTripString(allowed func(r rune) bool) func(i int64, f float64, s string, b bool) (any, any, bool) {
+	return func(i int64, f float64, s string, b bool) (any, any, bool) {
+		s = strings.Map(func(r rune) rune {
+			if allowed(r) {
+				return r
+			}
+			return -1
+		}, s)
+		return s, s, s != ""
+	}
+}
+
+func FuzzRoundTrip(f *testing.F) {
+	f.Add(int64(477160), 1.5, "ballspell", true)
+	f.Add(int64(-1), -0.000123, "a/c/d", false)
+	f.Add(int64(0), 1e21, "user@host:8080", true)
+	f.Add(int64(math.MinInt64), math.MaxFloat64, "~a.b-c_d", false)
+	f.Add(int64(0xFFFD), math.SmallestNonzeroFloat64, "!$&'()*+,;=", true)
+	f.Fuzz(func(t *testing.T, i int64, fl float64, s string, b bool) {
+		for verb, generate := range roundTripVerbs {
+			arg, want, ok := generate(i, fl, s, b)
+			if !ok {
+				continue
+			}
+			u := URL("%s://example.com/a/%" + verb + "/b")
+			filled := u.Fill(arg)
+			args, err := u.ExtractArgsErr(filled)
+			if err != nil {
+				t.Errorf("%%%s: could not extract %#v from %q: %v", verb, arg, filled, err)
+			} else if !reflect.DeepEqual(args, []any{want}) {
+				t.Errorf("%%%s: expected %q to extract %#v, got %#v", verb, filled, want, args)
+			}
+		}
+	})
+}
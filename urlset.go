@@ -0,0 +1,194 @@
+package urlfmt
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"regexp"
+	"strings"
+)
+
+// urlSetEntry is a named URL within a URLSet, along with its compiled regex.
+type urlSetEntry struct {
+	name    string
+	url     URL
+	pattern *regexp.Regexp
+}
+
+// URLSet is an ordered set of named URL formats that can be used to route an arbitrary URL to the format that it
+// belongs to. The regex for each URL is compiled once, when it is added to the set.
+type URLSet struct {
+	entries []urlSetEntry
+}
+
+// NewURLSet creates an empty URLSet.
+func NewURLSet() *URLSet {
+	return &URLSet{entries: make([]urlSetEntry, 0)}
+}
+
+// Add adds the given URL to the end of the URLSet under the given name. An error is returned if the name is already
+// used within the URLSet, or if the URL does not produce a valid regex.
+func (s *URLSet) Add(name string, u URL) error {
+	if _, ok := s.Get(name); ok {
+		return fmt.Errorf("URLSet already contains a URL named %q", name)
+	}
+
+	pattern, err := u.RegexErr()
+	if err != nil {
+		return errors.Wrapf(err, "cannot add %q to URLSet", name)
+	}
+	s.entries = append(s.entries, urlSetEntry{name: name, url: u, pattern: pattern})
+	return nil
+}
+
+// MustAdd is the same as Add, but will panic if an error occurs.
+func (s *URLSet) MustAdd(name string, u URL) *URLSet {
+	if err := s.Add(name, u); err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Get returns the URL with the given name.
+func (s *URLSet) Get(name string) (u URL, ok bool) {
+	for _, entry := range s.entries {
+		if entry.name == name {
+			return entry.url, true
+		}
+	}
+	return
+}
+
+// Names returns the names of the URLs within the URLSet in the order that they were added.
+func (s *URLSet) Names() []string {
+	names := make([]string, len(s.entries))
+	for i, entry := range s.entries {
+		names[i] = entry.name
+	}
+	return names
+}
+
+// Len returns the number of URLs within the URLSet.
+func (s *URLSet) Len() int {
+	return len(s.entries)
+}
+
+// Match finds the first URL within the URLSet, in the order that they were added, that the given URL matches. The
+// name of the matched URL, the URL itself, and the args extracted from the given URL are returned. If no URL within the
+// URLSet matches, then ok will be false.
+func (s *URLSet) Match(url string) (name string, u URL, args []any, ok bool) {
+	for _, entry := range s.entries {
+		var err error
+		if args, err = entry.url.extract(entry.pattern, url); err == nil {
+			return entry.name, entry.url, args, true
+		}
+	}
+	return "", "", nil, false
+}
+
+// Ambiguities returns each pair of names of the URLs within the URLSet that can match the same URL, in the order that
+// they were added. As Match returns the first URL that matches, the later URL of each pair will never be matched for
+// the URLs that both match, which is usually a mistake in the configuration of the URLSet. For example,
+// "%s://example.com/%s" and "%s://example.com/%d" are ambiguous, as a numeric path matches both. As are
+// "%s://example.com/app/%d" and "%s://example.com/app/%d/reviews", as the former matches a prefix of the latter.
+//
+// Rather than intersecting the regexes of the URLs, which is hard, each URL is filled with a handful of sample args
+// that match its verbs (see sampleURLs), and then matched against every other URL. This catches the common cases of
+// URLs that have the same structure but different verbs, and URLs that are a prefix of other URLs, but may miss pairs
+// that only match the same URL for unusual args.
+func (s *URLSet) Ambiguities() [][2]string {
+	samples := make([][]string, len(s.entries))
+	for i, entry := range s.entries {
+		samples[i] = sampleURLs(entry.url)
+	}
+
+	matchesAny := func(entry urlSetEntry, urls []string) bool {
+		for _, url := range urls {
+			if _, err := entry.url.extract(entry.pattern, url); err == nil {
+				return true
+			}
+		}
+		return false
+	}
+
+	ambiguities := make([][2]string, 0)
+	for i, entry := range s.entries {
+		for j := i + 1; j < len(s.entries); j++ {
+			other := s.entries[j]
+			if matchesAny(entry, samples[j]) || matchesAny(other, samples[i]) {
+				ambiguities = append(ambiguities, [2]string{entry.name, other.name})
+			}
+		}
+	}
+	return ambiguities
+}
+
+// Validate returns an error describing each pair of ambiguous URLs within the URLSet (see Ambiguities). If there are no
+// ambiguous URLs then nil is returned.
+func (s *URLSet) Validate() error {
+	ambiguities := s.Ambiguities()
+	if len(ambiguities) == 0 {
+		return nil
+	}
+	pairs := make([]string, len(ambiguities))
+	for i, pair := range ambiguities {
+		pairs[i] = fmt.Sprintf("%q and %q", pair[0], pair[1])
+	}
+	return fmt.Errorf("URLSet contains ambiguous URLs: %s", strings.Join(pairs, ", "))
+}
+
+// sampleArgs are the candidate args that are used to fill the verbs of a URL format within sampleURLs and Example. They
+// cover the output of most of the verbs, including IPv6 hosts, so that the verbs of two URL formats can be compared by
+// the args that they share.
+var sampleArgs = []string{"1", "a", "-1", "1.5", "1e+06", "true", "0x1", "a-b", "U+0031", "::1"}
+
+// sampleReplacer replaces the escaped percent signs and the markers of optional sections within the literal text of a
+// URL format for sampleURLs.
+var sampleReplacer = strings.NewReplacer("%%", "%", optionalOpen, "", optionalClose, "")
+
+// sampleURLs returns a URL for each of the sampleArgs, where each verb of the given URL format is filled textually with
+// that arg, or the next of the sampleArgs that the regex for the verb fully matches. Optional sections are included,
+// and repeated query parameter markers are filled with a single value. The protocol is always "https".
+func sampleURLs(u URL) []string {
+	samples := make([]string, 0, len(sampleArgs))
+	for i := range sampleArgs {
+		i := i
+		samples = append(samples, sampleURL(u, func(verb string, pattern *regexp.Regexp) string {
+			arg := sampleArgs[i]
+			for j := 0; j < len(sampleArgs) && !pattern.MatchString(arg); j++ {
+				arg = sampleArgs[(i+j+1)%len(sampleArgs)]
+			}
+			return arg
+		}))
+	}
+	return samples
+}
+
+// sampleURL fills the given URL format with the args returned by the given pick function, which is called for each verb
+// with the verb (without the "%") and a regex that matches the entire arg for that verb at its position in the URL
+// format. The protocol is always "https", and each repeated query parameter marker is filled with a single "key=a"
+// pair.
+func sampleURL(u URL, pick func(verb string, pattern *regexp.Regexp) string) string {
+	format := missingVerbPattern.ReplaceAllString(u.withProtocol(noProtocol), "%$1")
+	var b strings.Builder
+	if u.hasProtocolVerb() {
+		b.WriteString(string(httpsProtocol))
+	}
+	last := 0
+	for _, loc := range findTokens(format) {
+		b.WriteString(sampleReplacer.Replace(format[last:loc[0]]))
+		last = loc[1]
+		if loc[4] >= 0 {
+			b.WriteString(format[loc[4]:loc[5]] + "=a")
+			continue
+		}
+
+		verb := format[loc[2]:loc[3]]
+		pattern, err := regexp.Compile(`\A(?:` + verbRegexAt(format, loc[0], verb) + `)\z`)
+		if err != nil {
+			pattern = regexp.MustCompile(`\A\z`)
+		}
+		b.WriteString(pick(verb, pattern))
+	}
+	b.WriteString(sampleReplacer.Replace(format[last:]))
+	return b.String()
+}
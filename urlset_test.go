@@ -0,0 +1,61 @@
+package urlfmt
+
+import "fmt"
+
+func ExampleURLSet_Match() {
+	set := NewURLSet().
+		MustAdd("steam_app_page", "%s://store.steampowered.com/app/%d").
+		MustAdd("steam_app_reviews", "%s://store.steampowered.com/appreviews/%d?json=1&cursor=%s").
+		MustAdd("itch_io_game_page", "%s://%s.itch.io/%s")
+
+	for _, url := range []string{
+		"https://store.steampowered.com/app/477160/Human_Fall_Flat/",
+		"https://store.steampowered.com/appreviews/477160?json=1&cursor=abc",
+		"https://hempuli.itch.io/baba-files-taxes",
+		"https://example.com",
+	} {
+		if name, u, args, ok := set.Match(url); ok {
+			fmt.Println(name, u, args)
+		} else {
+			fmt.Println("no match for", url)
+		}
+	}
+	// Output:
+	// steam_app_page %s://store.steampowered.com/app/%d [477160]
+	// steam_app_reviews %s://store.steampowered.com/appreviews/%d?json=1&cursor=%s [477160 abc]
+	// itch_io_game_page %s://%s.itch.io/%s [hempuli baba-files-taxes]
+	// no match for https://example.com
+}
+
+func ExampleURLSet_Add() {
+	set := NewURLSet()
+	fmt.Println(set.Add("steam_app_page", "%s://store.steampowered.com/app/%d"))
+	fmt.Println(set.Add("steam_app_page", "%s://store.steampowered.com/app/%d"))
+	fmt.Println(set.Add("invalid", "%s://store.steampowered.com%{:%d/app/%d") != nil)
+	fmt.Println(set.Names())
+	// Output:
+	// <nil>
+	// URLSet already contains a URL named "steam_app_page"
+	// true
+	// [steam_app_page]
+}
+
+func ExampleURLSet_Ambiguities() {
+	set := NewURLSet().
+		MustAdd("steam_app_page", "%s://store.steampowered.com/app/%d").
+		MustAdd("itch_io_game_page", "%s://%s.itch.io/%s").
+		MustAdd("steam_app_page_by_name", "%s://store.steampowered.com/app/%s").
+		MustAdd("steam_app_reviews", "%s://store.steampowered.com/app/%d/reviews?l=%s").
+		MustAdd("itch_io_search", "%s://itch.io/search?q=%s")
+
+	fmt.Println(set.Ambiguities())
+	fmt.Println(set.Validate())
+	fmt.Println(NewURLSet().
+		MustAdd("steam_app_page", "%s://store.steampowered.com/app/%d").
+		MustAdd("itch_io_game_page", "%s://%s.itch.io/%s").
+		Validate())
+	// Output:
+	// [[steam_app_page steam_app_page_by_name] [steam_app_page steam_app_reviews] [steam_app_page_by_name steam_app_reviews]]
+	// URLSet contains ambiguous URLs: "steam_app_page" and "steam_app_page_by_name", "steam_app_page" and "steam_app_reviews", "steam_app_page_by_name" and "steam_app_reviews"
+	// <nil>
+}
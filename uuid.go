@@ -0,0 +1,32 @@
+package urlfmt
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// UUIDPattern is a regex pattern that matches a UUID in its canonical textual form, e.g.
+// "123e4567-e89b-12d3-a456-426614174000". It can be registered as a custom verb, along with ParseUUID, so that a URL
+// format only matches URLs that contain a well-formed UUID, rather than any slug (as with "%s"):
+//
+//	err := RegisterVerb('u', UUIDPattern, ParseUUID)
+//	const UserPage URL = "%s://example.com/users/%u"
+//	args, err := UserPage.ExtractArgsErr("https://example.com/users/123e4567-e89b-12d3-a456-426614174000")
+//
+// As custom verbs are filled using "%v", a UUID can be filled using either a string or any type that implements
+// fmt.Stringer and produces the canonical textual form.
+const UUIDPattern = `([0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12})`
+
+// uuidPattern is the compiled UUIDPattern, anchored to match an entire string.
+var uuidPattern = regexp.MustCompile(`\A` + UUIDPattern + `\z`)
+
+// ParseUUID parses a string matched by UUIDPattern into a string holding the UUID in lower-case, so that UUIDs that
+// only differ by case are extracted as the same value. It is intended to be used as the parser for a custom verb
+// registered with UUIDPattern (see RegisterVerb).
+func ParseUUID(s string) (any, error) {
+	if !uuidPattern.MatchString(s) {
+		return nil, fmt.Errorf("%q is not a UUID", s)
+	}
+	return strings.ToLower(s), nil
+}
@@ -0,0 +1,56 @@
+package urlfmt
+
+import (
+	"fmt"
+	"testing"
+)
+
+func ExampleUUIDPattern() {
+	if err := RegisterVerb('u', UUIDPattern, ParseUUID); err != nil {
+		panic(err)
+	}
+
+	const UserPage URL = "%s://example.com/users/%u/posts/%d"
+	fmt.Println(UserPage.ExtractArgsErr("https://example.com/users/123E4567-E89B-12D3-A456-426614174000/posts/3"))
+	fmt.Println(UserPage.Match("https://example.com/users/hempuli/posts/3"))
+	// Output:
+	// [123e4567-e89b-12d3-a456-426614174000 3] <nil>
+	// false
+}
+
+func TestParseUUID(t *testing.T) {
+	if err := RegisterVerb('u', UUIDPattern, ParseUUID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	const UserPage URL = "%s://example.com/users/%u/posts"
+	for _, test := range []struct {
+		uuid  string
+		valid bool
+	}{
+		{"123e4567-e89b-12d3-a456-426614174000", true},
+		{"00000000-0000-0000-0000-000000000000", true},
+		{"123e4567e89b12d3a456426614174000", false},
+		{"123e4567-e89b-12d3-a456-42661417400", false},
+		{"123e4567-e89b-12d3-a456-4266141740000", false},
+		{"g23e4567-e89b-12d3-a456-426614174000", false},
+		{"{123e4567-e89b-12d3-a456-426614174000}", false},
+	} {
+		uuid, err := ParseUUID(test.uuid)
+		switch {
+		case test.valid && (err != nil || uuid != test.uuid):
+			t.Errorf("expected %q to be parsed as is, got %v (%v)", test.uuid, uuid, err)
+		case !test.valid && err == nil:
+			t.Errorf("expected an error for %q, got %v", test.uuid, uuid)
+		}
+
+		url := UserPage.Fill(test.uuid)
+		args, err := UserPage.ExtractArgsErr(url)
+		switch {
+		case test.valid && (err != nil || len(args) != 1 || args[0] != test.uuid):
+			t.Errorf("expected [%s] to be extracted from %q, got %v (%v)", test.uuid, url, args, err)
+		case !test.valid && err == nil:
+			t.Errorf("expected %s not to match %q, extracted %v", UserPage, url, args)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package urlfmt
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// verbsMu guards verbToRegexMapping, verbKinds, regexParsers, and customVerbs, so that verbs can be registered using
+// RegisterVerb whilst other goroutines are using URL formats.
+var verbsMu sync.RWMutex
+
+// customVerbs is the set of verbs registered using RegisterVerb that are not understood by the fmt package. These verbs
+// are filled using "%v".
+var customVerbs = make(map[string]struct{})
+
+// builtinVerbs is the set of verbs that are built in to the package.
+var builtinVerbs = func() map[string]struct{} {
+	verbs := make(map[string]struct{}, len(verbToRegexMapping))
+	for verb := range verbToRegexMapping {
+		verbs[verb] = struct{}{}
+	}
+	return verbs
+}()
+
+// RegisterVerb registers a custom string interpolation verb, so that it can be used within URL formats. The pattern is
+// the regex that the verb is converted to by URL.Regex, and must consist of a single capturing group, e.g.
+// `([0-9a-zA-Z]+)`. The parser is used by URL.ExtractArgs to parse strings matched by the pattern. If the parser is
+// nil, then the matched string will be extracted as is. Verbs that are not understood by the fmt package are filled
+// using "%v". RegisterVerb is safe to call at init time, as well as concurrently with the use of URL formats.
+//
+// An error is returned if the letter is not an ASCII letter, if the pattern is not valid, if the letter is already used
+// by a built-in verb, or if the pattern is already used by another verb. Use ForceRegisterVerb to override built-in
+// verbs.
+//
+//	err := RegisterVerb('z', `([0-9a-zA-Z]+)`, func(s string) (any, error) { return decodeBase62(s) })
+func RegisterVerb(letter rune, pattern string, parser func(s string) (any, error)) error {
+	return registerVerb(letter, pattern, parser, false)
+}
+
+// ForceRegisterVerb is the same as RegisterVerb, except that built-in verbs, and verbs using the same pattern, will be
+// overridden rather than an error being returned.
+func ForceRegisterVerb(letter rune, pattern string, parser func(s string) (any, error)) error {
+	return registerVerb(letter, pattern, parser, true)
+}
+
+func registerVerb(letter rune, pattern string, parser func(s string) (any, error), force bool) error {
+	verb := string(letter)
+	if !verbPattern.MatchString("%"+verb) || verb == string(wildcardVerb) {
+		return fmt.Errorf("%q is not a valid verb, verbs must be an ASCII letter", letter)
+	}
+
+	if _, err := regexp.Compile(pattern); err != nil {
+		return fmt.Errorf("pattern %q for verb %%%s is not a valid regex: %v", pattern, verb, err)
+	}
+	if groups := captureGroups(pattern); len(groups) != 1 || groups[0] != pattern {
+		return fmt.Errorf("pattern %q for verb %%%s must consist of a single capturing group", pattern, verb)
+	}
+
+	verbsMu.Lock()
+	defer verbsMu.Unlock()
+	if !force {
+		if _, ok := builtinVerbs[verb]; ok {
+			return fmt.Errorf("%%%s is a built-in verb, use ForceRegisterVerb to override it", verb)
+		}
+		for other, otherPattern := range verbToRegexMapping {
+			if other != verb && otherPattern == pattern {
+				return fmt.Errorf("pattern %q for verb %%%s is already used by %%%s", pattern, verb, other)
+			}
+		}
+	}
+
+	if _, ok := builtinVerbs[verb]; !ok {
+		customVerbs[verb] = struct{}{}
+	}
+	verbToRegexMapping[verb] = pattern
+	verbKinds[verb] = reflect.String
+	delete(regexParsers, pattern)
+	if parser != nil {
+		verbKinds[verb] = reflect.Interface
+		regexParsers[pattern] = parser
+	}
+	return nil
+}
+
+// verbRegex returns the regex pattern that the given verb is converted to by URL.Regex. If the verb is not known, then
+// false is returned, and the verb is converted straight to a regex character set if it is a Perl character class,
+// e.g. w -> (\w+). Any other unknown verb is matched literally, e.g. k -> (k+), as escaping it would produce an invalid
+// regex, e.g. "\k".
+func verbRegex(verb string) (pattern string, ok bool) {
+	verbsMu.RLock()
+	defer verbsMu.RUnlock()
+	if pattern, ok = verbToRegexMapping[verb]; !ok {
+		letter := verb[len(verb)-1:]
+		if strings.Contains("dDsSwW", letter) {
+			pattern = fmt.Sprintf(`(\%s+)`, letter)
+		} else {
+			pattern = fmt.Sprintf(`(%s+)`, letter)
+		}
+	}
+	return
+}
+
+// knownVerb returns whether the given verb is either built in, or has been registered using RegisterVerb.
+func knownVerb(verb string) bool {
+	_, ok := verbRegex(verb)
+	return ok
+}
+
+// verbKind returns the reflect.Kind of the value that URL.ExtractArgs will produce for the given verb.
+func verbKind(verb string) reflect.Kind {
+	verbsMu.RLock()
+	defer verbsMu.RUnlock()
+	if kind, ok := verbKinds[verb]; ok {
+		return kind
+	}
+	return reflect.String
+}
+
+// fmtFormat replaces any custom verbs (see RegisterVerb) within the given format with "%v", and any wildcard verbs with
+// "%s", so that the format can be passed to fmt.Sprintf.
+func fmtFormat(format string) string {
+	verbsMu.RLock()
+	defer verbsMu.RUnlock()
+	if len(customVerbs) == 0 && !strings.Contains(format, "%"+string(wildcardVerb)) {
+		return format
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range verbPattern.FindAllStringSubmatchIndex(format, -1) {
+		replacement := "%v"
+		if verb := format[loc[2]:loc[3]]; verb == string(wildcardVerb) {
+			replacement = "%s"
+		} else if _, ok := customVerbs[verb]; !ok {
+			continue
+		}
+		if escapedAt(format, loc[0]) {
+			continue
+		}
+		b.WriteString(format[last:loc[0]])
+		b.WriteString(replacement)
+		last = loc[1]
+	}
+	b.WriteString(format[last:])
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package urlfmt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const base62Alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+type base62 int64
+
+func (n base62) String() string {
+	if n == 0 {
+		return "0"
+	}
+	var digits []byte
+	for ; n > 0; n /= 62 {
+		digits = append([]byte{base62Alphabet[n%62]}, digits...)
+	}
+	return string(digits)
+}
+
+func parseBase62(s string) (any, error) {
+	var n base62
+	for _, r := range s {
+		i := strings.IndexRune(base62Alphabet, r)
+		if i < 0 {
+			return nil, fmt.Errorf("%q is not a base-62 digit", r)
+		}
+		n = n*62 + base62(i)
+	}
+	return n, nil
+}
+
+func TestRegisterVerb(t *testing.T) {
+	if err := RegisterVerb('z', `([0-9a-zA-Z]+)`, parseBase62); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	const ShortLink URL = "%s://example.com/v/%z/comments/%d"
+	for _, n := range []base62{0, 61, 62, 477160, 1 << 40} {
+		url := ShortLink.Fill(n, 3)
+		if expected := fmt.Sprintf("https://example.com/v/%s/comments/3", n); url != expected {
+			t.Fatalf("expected %q, got %q", expected, url)
+		}
+		args, err := ShortLink.ExtractArgsErr(url)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(args) != 2 || args[0] != n || args[1] != int64(3) {
+			t.Errorf("extracted %v from %q, expected [%d 3]", args, url, n)
+		}
+	}
+	if err := ShortLink.Validate(); err != nil {
+		t.Errorf("expected %s to be valid, got %v", ShortLink, err)
+	}
+
+	for _, test := range []struct {
+		letter  rune
+		pattern string
+	}{
+		{'d', `([0-9a-zA-Z]+)`},
+		{'x', `([0-9a-zA-Z]+)`},
+		{'y', `([+-]?\d+)`},
+		{'y', `([0-9a-zA-Z]+)`},
+		{'y', `([a-z]+`},
+		{'y', `[a-z]+`},
+		{'y', `([a-z]+)/([a-z]+)`},
+		{'1', `([a-z]+)`},
+		{'é', `([a-z]+)`},
+	} {
+		if err := RegisterVerb(test.letter, test.pattern, nil); err == nil {
+			t.Errorf("expected an error when registering %%%c as %s", test.letter, test.pattern)
+		}
+	}
+}